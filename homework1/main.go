@@ -4,7 +4,9 @@ package main
 import (
 	"fmt"
 	"log"
+	"os"
 
+	"symbolic-execution-course/internal/cfg"
 	"symbolic-execution-course/internal/ssa"
 )
 
@@ -33,4 +35,26 @@ func testFunction(x int) int {
 		log.Fatalf("Ошибка построения SSA: %v", err)
 	}
 	fmt.Printf("CFG построен для функции с %d блоками\n", len(graph.Blocks))
+
+	// Загружаем пакет с примерами целиком (запуск из корня модуля)
+	prog, err := builder.Load("", "./homework1/examples")
+	if err != nil {
+		log.Fatalf("Ошибка загрузки пакета: %v", err)
+	}
+	for _, name := range []string{"simpleIf", "nestedLoops", "withGoto"} {
+		fn, err := prog.Func(name)
+		if err != nil {
+			log.Fatalf("Ошибка поиска функции: %v", err)
+		}
+		fmt.Printf("%s: %d блоков\n", fn.Name(), len(fn.Blocks))
+	}
+
+	// CFG в формате DOT: go run ./homework1 | dot -Tpng
+	fn, err := prog.Func("withGoto")
+	if err != nil {
+		log.Fatalf("Ошибка поиска функции: %v", err)
+	}
+	if err := cfg.New(fn).WriteDOT(os.Stdout); err != nil {
+		log.Fatalf("Ошибка экспорта CFG: %v", err)
+	}
 }
@@ -4,6 +4,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"symbolic-execution-course/internal/memory"
 	"symbolic-execution-course/internal/symbolic"
 	"symbolic-execution-course/internal/translator"
 )
@@ -29,7 +30,7 @@ func main() {
 	fmt.Printf("Тип выражения: %s\n", condition.Type().String())
 
 	// Создаём Z3 транслятор
-	translator := translator.NewZ3Translator()
+	translator := translator.NewZ3Translator(memory.NewSymbolicMemory())
 	defer translator.Close()
 
 	// Транслируем в Z3
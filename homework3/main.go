@@ -5,6 +5,7 @@ import (
 	"symbolic-execution-course/internal/memory"
 	"symbolic-execution-course/internal/symbolic"
 	"symbolic-execution-course/internal/translator"
+	"symbolic-execution-course/pkg/z3wrapper"
 
 	"github.com/ebukreev/go-z3/z3"
 )
@@ -16,23 +17,21 @@ func translateAndPrintRes(translator *translator.Z3Translator, expr symbolic.Sym
 
 func main() {
 	var mem = memory.NewSymbolicMemory()
-	translator := translator.NewZ3Translator()
-	defer translator.Close()
-	array_proto := symbolic.NewSymbolicVariable("array", symbolic.ObjectType)
-	var array = mem.Allocate(symbolic.ArrayType, "[]int")
-	array.Expr = array_proto
+	zt := translator.NewZ3Translator(mem)
+	defer zt.Close()
+	var array = mem.AllocateArray(symbolic.InnerType{ExprTy: symbolic.IntType}, symbolic.NewIntConstant(11))
 
-	mem.AssignToArray(array, 5, symbolic.NewIntConstant(10))
+	mem.AssignToArray(array, symbolic.NewIntConstant(5), symbolic.NewIntConstant(10))
 
-	var fromArray = mem.GetFromArray(array, 5, symbolic.InnerType{ExprTy: symbolic.IntType})
+	var fromArray = mem.GetFromArray(array, symbolic.NewIntConstant(5), symbolic.InnerType{ExprTy: symbolic.IntType})
 	println(fromArray)
 
-	var anotherFromArray = mem.GetFromArray(array, 10, symbolic.InnerType{ExprTy: symbolic.IntType})
+	var anotherFromArray = mem.GetFromArray(array, symbolic.NewIntConstant(10), symbolic.InnerType{ExprTy: symbolic.IntType})
 	println(anotherFromArray)
 
 	println("\n======================================================\n")
 
-	translateAndPrintRes(translator, fromArray, "fromArray")
+	translateAndPrintRes(zt, fromArray, "fromArray")
 
 	// type Person struct {
 	// 	Age  int
@@ -51,13 +50,13 @@ func main() {
 	p_Age := symbolic.NewSymbolicVariableArray("p_Age", symbolic.InnerType{ExprTy: symbolic.IntType})
 	// p.Age = 25
 	ageAssign := symbolic.NewBinaryOperation(p_Age, symbolic.NewIntConstant(25), symbolic.FIELD_ASSIGN)
-	translateAndPrintRes(translator, ageAssign, "testStructBasic")
+	translateAndPrintRes(zt, ageAssign, "testStructBasic")
 
 	// p.ID
 	p_ID := symbolic.NewSymbolicVariableArray("p_ID", symbolic.InnerType{ExprTy: symbolic.IntType})
 	// p.ID = 1001
 	idAssign := symbolic.NewBinaryOperation(p_ID, symbolic.NewIntConstant(1001), symbolic.FIELD_ASSIGN)
-	translateAndPrintRes(translator, idAssign, "testStructBasic")
+	translateAndPrintRes(zt, idAssign, "testStructBasic")
 
 	//	func testStructModification(p Person) Person {
 	//		p.Age = p.Age + 1
@@ -66,22 +65,23 @@ func main() {
 	//	}
 	Addition := symbolic.NewBinaryOperation(p_Age, symbolic.NewIntConstant(1), symbolic.ADD)
 	ageAssign = symbolic.NewBinaryOperation(p_Age, Addition, symbolic.FIELD_ASSIGN)
-	translateAndPrintRes(translator, ageAssign, "testStructModification")
+	translateAndPrintRes(zt, ageAssign, "testStructModification")
 	mul := symbolic.NewBinaryOperation(p_ID, symbolic.NewIntConstant(2), symbolic.MUL)
 	idAssign = symbolic.NewBinaryOperation(p_ID, mul, symbolic.FIELD_ASSIGN)
-	translateAndPrintRes(translator, idAssign, "testStructModification")
+	translateAndPrintRes(zt, idAssign, "testStructModification")
 
 	// func testStructPointer() *Person {
 	// 	p := &Person{Name: "Bob", Age: 30, ID: 2002}
 	// 	p.Age = p.Age + 5
 	// 	return p
 	// }
+	// A new memory needs its own translator: the translator dereferences through its memory
 	mem = memory.NewSymbolicMemory()
+	zt = translator.NewZ3Translator(mem)
+	defer zt.Close()
 
 	// We should allocate as follows:
-	p_proto := symbolic.NewSymbolicVariable("p", symbolic.ObjectType)
-	p := mem.Allocate(symbolic.ObjectType, "Person")
-	p.Expr = p_proto
+	p := mem.AllocateStruct("Person")
 
 	mem.AssignField(p, 0, symbolic.NewIntConstant(30))   // Initialize Age
 	mem.AssignField(p, 1, symbolic.NewIntConstant(2002)) // Initialize ID
@@ -92,7 +92,7 @@ func main() {
 	mem.AssignField(p, 0, Addition)
 
 	extractField = mem.GetFieldValue(p, 0, symbolic.InnerType{ExprTy: symbolic.IntType})
-	translateAndPrintRes(translator, extractField, "testStructPointer")
+	translateAndPrintRes(zt, extractField, "testStructPointer")
 
 	// type Foo struct {
 	// 	a int
@@ -107,34 +107,41 @@ func main() {
 	// 	return 5
 	// }
 
-	foo1_proto := symbolic.NewSymbolicVariable("foo1", symbolic.ObjectType)
-	foo1 := mem.Allocate(symbolic.ObjectType, "Foo")
-	foo1.Expr = foo1_proto
-
-	foo2_proto := symbolic.NewSymbolicVariable("foo2", symbolic.ObjectType)
-	foo2 := mem.Allocate(symbolic.ObjectType, "Foo")
-	foo2.Expr = foo2_proto
+	// Parameters are pointers with symbolic addresses: the solver decides whether they alias
+	foo1 := mem.InputRef("foo1", symbolic.Object, "Foo")
+	foo2 := mem.InputRef("foo2", symbolic.Object, "Foo")
 
 	// Our function:
-	foo2_assign := mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
-	translator.TranslateExpression(foo2_assign) // We have to translate it, or the assignment will not be performed by the solver
-	foo1_assign := mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
-	translator.TranslateExpression(foo1_assign)
+	mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
+	mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
 
 	foo2_a := mem.GetFieldValue(foo2, 0, symbolic.InnerType{ExprTy: symbolic.IntType})
 	cond := symbolic.NewBinaryOperation(foo2_a, symbolic.NewIntConstant(2), symbolic.EQ)
 	ifStmt := symbolic.NewTernaryOperation(cond, symbolic.NewIntConstant(4), symbolic.NewIntConstant(5))
-	z3Expr, _ := translator.TranslateExpression(ifStmt)
+	z3Expr, _ := zt.TranslateExpression(ifStmt)
 
 	foo2_a2 := mem.GetFieldValue(foo2, 0, symbolic.InnerType{ExprTy: symbolic.IntType})
-	foo2_a2_z3Expr, _ := translator.TranslateExpression(foo2_a2)
+	foo2_a2_z3Expr, _ := zt.TranslateExpression(foo2_a2)
 	foo1_a2 := mem.GetFieldValue(foo1, 0, symbolic.InnerType{ExprTy: symbolic.IntType})
-	foo1_a2_z3Expr, _ := translator.TranslateExpression(foo1_a2)
+	foo1_a2_z3Expr, _ := zt.TranslateExpression(foo1_a2)
+
+	var heapAxioms []z3.Bool
+	for _, c := range mem.Constraints() {
+		z, _ := zt.TranslateExpression(c)
+		heapAxioms = append(heapAxioms, z.(z3.Bool))
+	}
+	newSolver := func() *z3.Solver {
+		s := z3.NewSolver(zt.GetContext())
+		for _, axiom := range heapAxioms {
+			s.Assert(axiom)
+		}
+		return s
+	}
 
 	println("ALIASING CHECKS:")
-	ints := translator.GetContext().IntSort()
-	s := z3.NewSolver(translator.GetContext())
-	six := translator.GetContext().FromInt(6, ints)
+	ints := zt.GetContext().IntSort()
+	s := newSolver()
+	six := zt.GetContext().FromInt(6, ints)
 	assertion := z3Expr.(z3.Int).Eq(six.(z3.Int))
 	s.Assert(assertion)
 	sat, _ := s.Check()
@@ -145,8 +152,8 @@ func main() {
 	}
 	fmt.Printf("======================================\n")
 
-	s = z3.NewSolver(translator.GetContext())
-	five := translator.GetContext().FromInt(5, ints)
+	s = newSolver()
+	five := zt.GetContext().FromInt(5, ints)
 	assertion = z3Expr.(z3.Int).Eq(five.(z3.Int))
 	s.Assert(assertion)
 	sat, _ = s.Check()
@@ -157,7 +164,7 @@ func main() {
 	}
 	fmt.Printf("======================================\n")
 
-	s = z3.NewSolver(translator.GetContext())
+	s = newSolver()
 	assertion = z3Expr.(z3.Int).Eq(five.(z3.Int))
 	assertion2 := foo2_a2_z3Expr.(z3.Int).Eq(foo1_a2_z3Expr.(z3.Int))
 	s.Assert(assertion)
@@ -170,8 +177,8 @@ func main() {
 	}
 	fmt.Printf("======================================\n")
 
-	s = z3.NewSolver(translator.GetContext())
-	four := translator.GetContext().FromInt(4, ints)
+	s = newSolver()
+	four := zt.GetContext().FromInt(4, ints)
 	assertion = z3Expr.(z3.Int).Eq(four.(z3.Int))
 	s.Assert(assertion)
 	s.Assert(assertion2)
@@ -191,16 +198,42 @@ func main() {
 	// 	return arr
 	// }
 
-	arr_proto := symbolic.NewSymbolicVariableArray("arr", symbolic.InnerType{ExprTy: symbolic.IntType})
-	arr := mem.Allocate(symbolic.ObjectType, "[5]int")
-	arr.Expr = arr_proto
+	arr := mem.AllocateArray(symbolic.InnerType{ExprTy: symbolic.IntType}, symbolic.NewIntConstant(5))
 
 	var assignments [5]symbolic.SymbolicExpression
 	for i := int64(0); i < 5; i++ {
 		mul_i := symbolic.NewBinaryOperation(symbolic.NewIntConstant(i), symbolic.NewIntConstant(i), symbolic.MUL)
-		arr_i_assign := mem.AssignField(arr, int(i), mul_i)
+		arr_i_assign := mem.AssignToArray(arr, symbolic.NewIntConstant(i), mul_i)
 		assignments[i] = arr_i_assign
 	}
-	arr_4 := mem.GetFieldValue(arr, 4, symbolic.InnerType{ExprTy: symbolic.IntType})
-	translateAndPrintRes(translator, arr_4, "testArrayFixed")
+	arr_4 := mem.GetFromArray(arr, symbolic.NewIntConstant(4), symbolic.InnerType{ExprTy: symbolic.IntType})
+	translateAndPrintRes(zt, arr_4, "testArrayFixed")
+
+	// func testArrayModification(arr [5]int) [5]int {
+	// 	for i := range arr {
+	// 		arr[i] = arr[i] + 1
+	// 	}
+	// 	return arr
+	// }
+
+	// One iteration with symbolic index i
+	input := mem.InputArray("arr", symbolic.InnerType{ExprTy: symbolic.IntType}, symbolic.NewIntConstant(5))
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	arr_i := mem.GetFromArray(input, i, symbolic.InnerType{ExprTy: symbolic.IntType})
+	mem.AssignToArray(input, i, symbolic.NewBinaryOperation(arr_i, symbolic.NewIntConstant(1), symbolic.ADD))
+	arr_i = mem.GetFromArray(input, i, symbolic.InnerType{ExprTy: symbolic.IntType})
+	translateAndPrintRes(zt, arr_i, "testArrayModification")
+
+	// Inside the range loop 0 <= i < len(arr), so arr[i] cannot go out of bounds;
+	// without the loop condition the solver finds an offending index
+	inRange := []symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(0), symbolic.GE),
+		symbolic.NewBinaryOperation(i, mem.ArrayLen(input), symbolic.LT),
+	}
+	inLoop, _ := z3wrapper.FindPanics(zt, mem, inRange...)
+	fmt.Printf("testArrayModification: %d reachable panics inside the loop\n", len(inLoop))
+	anyIndex, _ := z3wrapper.FindPanics(zt, mem)
+	if len(anyIndex) > 0 {
+		fmt.Printf("arr[i] with unconstrained i: %s, witness %v\n", anyIndex[0].Panic.Kind, anyIndex[0].Values)
+	}
 }
@@ -0,0 +1,158 @@
+package cfg
+
+import "golang.org/x/tools/go/ssa"
+
+// DomTree - дерево доминаторов или постдоминаторов по индексам блоков.
+// Idom[b] - непосредственный (пост)доминатор блока b; у корней и блоков, которые
+// не достижимы от корней, он равен -1. Корни дерева доминаторов - входной блок
+// и блок восстановления (в него попадают по панике), постдоминаторов - блоки
+// без последователей (return, panic). Блоки бесконечного цикла, из которых
+// выхода нет, постдоминаторов не имеют
+type DomTree struct {
+	Idom     []int
+	Children [][]int
+	Roots    []int
+
+	reachable []bool
+}
+
+// Dominators строит дерево доминаторов функции fn
+func Dominators(fn *ssa.Function) *DomTree {
+	roots := []int{0}
+	if fn.Recover != nil {
+		roots = append(roots, fn.Recover.Index)
+	}
+	return newDomTree(len(fn.Blocks), roots,
+		func(b int) []*ssa.BasicBlock { return fn.Blocks[b].Preds },
+		func(b int) []*ssa.BasicBlock { return fn.Blocks[b].Succs })
+}
+
+// PostDominators строит дерево постдоминаторов функции fn: доминаторы
+// обращённого графа с общим фиктивным выходом
+func PostDominators(fn *ssa.Function) *DomTree {
+	var roots []int
+	for _, b := range fn.Blocks {
+		if len(b.Succs) == 0 {
+			roots = append(roots, b.Index)
+		}
+	}
+	return newDomTree(len(fn.Blocks), roots,
+		func(b int) []*ssa.BasicBlock { return fn.Blocks[b].Succs },
+		func(b int) []*ssa.BasicBlock { return fn.Blocks[b].Preds })
+}
+
+// Dominates сообщает, (пост)доминирует ли блок a над блоком b.
+// Каждый достижимый блок доминирует над собой
+func (t *DomTree) Dominates(a, b int) bool {
+	if !t.reachable[a] || !t.reachable[b] {
+		return false
+	}
+	for ; b != -1; b = t.Idom[b] {
+		if b == a {
+			return true
+		}
+	}
+	return false
+}
+
+// newDomTree вычисляет доминаторы итеративным алгоритмом Cooper-Harvey-Kennedy.
+// Корни подвешиваются к фиктивной вершине n, поэтому граф всегда с одним корнем
+func newDomTree(n int, roots []int, preds, succs func(int) []*ssa.BasicBlock) *DomTree {
+	virtual := n
+	succsOf := func(b int) []int {
+		if b == virtual {
+			return roots
+		}
+		return indices(succs(b))
+	}
+	isRoot := make([]bool, n)
+	for _, r := range roots {
+		isRoot[r] = true
+	}
+	predsOf := func(b int) []int {
+		res := indices(preds(b))
+		if isRoot[b] {
+			res = append(res, virtual)
+		}
+		return res
+	}
+
+	// Обратный постпорядок от фиктивного корня
+	postorder := make([]int, n+1)
+	for i := range postorder {
+		postorder[i] = -1
+	}
+	var order []int
+	visited := make([]bool, n+1)
+	var dfs func(b int)
+	dfs = func(b int) {
+		visited[b] = true
+		for _, s := range succsOf(b) {
+			if !visited[s] {
+				dfs(s)
+			}
+		}
+		postorder[b] = len(order)
+		order = append(order, b)
+	}
+	dfs(virtual)
+
+	idom := make([]int, n+1)
+	for i := range idom {
+		idom[i] = -1
+	}
+	idom[virtual] = virtual
+	intersect := func(a, b int) int {
+		for a != b {
+			for postorder[a] < postorder[b] {
+				a = idom[a]
+			}
+			for postorder[b] < postorder[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+	for changed := true; changed; {
+		changed = false
+		for i := len(order) - 2; i >= 0; i-- {
+			b := order[i]
+			newIdom := -1
+			for _, p := range predsOf(b) {
+				if idom[p] == -1 {
+					continue
+				}
+				if newIdom == -1 {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if idom[b] != newIdom {
+				idom[b] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	t := &DomTree{Idom: make([]int, n), Children: make([][]int, n), Roots: roots, reachable: make([]bool, n)}
+	for b := 0; b < n; b++ {
+		t.reachable[b] = visited[b]
+		t.Idom[b] = idom[b]
+		if idom[b] == virtual {
+			t.Idom[b] = -1
+		}
+		if t.Idom[b] != -1 {
+			t.Children[t.Idom[b]] = append(t.Children[t.Idom[b]], b)
+		}
+	}
+	return t
+}
+
+func indices(blocks []*ssa.BasicBlock) []int {
+	res := make([]int, len(blocks))
+	for i, b := range blocks {
+		res[i] = b.Index
+	}
+	return res
+}
@@ -0,0 +1,144 @@
+// Package cfg содержит экспорт и анализ графа потока управления SSA функций
+package cfg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Graph - граф потока управления функции в виде, пригодном для сериализации.
+// JSON схема стабильна: блоки перечислены в порядке индексов, вход - блок 0
+type Graph struct {
+	Function string  `json:"function"`
+	Blocks   []Block `json:"blocks"`
+}
+
+// Block - базовый блок: индекс, комментарий SSA ("entry", "if.then", ...),
+// рёбра в последователи, индексы предшественников и инструкции
+type Block struct {
+	Index        int           `json:"index"`
+	Comment      string        `json:"comment,omitempty"`
+	Successors   []Edge        `json:"successors"`
+	Predecessors []int         `json:"predecessors"`
+	Instructions []Instruction `json:"instructions"`
+}
+
+// Edge - ребро графа. Kind - "true" или "false" для переходов по If, пусто для Jump.
+// Блок восстановления ("recover") входящих рёбер не имеет: в него попадают по панике
+type Edge struct {
+	Block int    `json:"block"`
+	Kind  string `json:"kind,omitempty"`
+}
+
+// Instruction - инструкция SSA: текст, вид (имя типа инструкции) и позиция в исходнике
+type Instruction struct {
+	Text string    `json:"text"`
+	Op   string    `json:"op"`
+	Pos  *Position `json:"pos,omitempty"`
+}
+
+// Position - позиция в исходном коде
+type Position struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// New строит сериализуемый граф функции fn
+func New(fn *ssa.Function) *Graph {
+	g := &Graph{Function: fn.String(), Blocks: []Block{}}
+	for _, b := range fn.Blocks {
+		block := Block{
+			Index:        b.Index,
+			Comment:      b.Comment,
+			Successors:   []Edge{},
+			Predecessors: []int{},
+			Instructions: []Instruction{},
+		}
+		for i, succ := range b.Succs {
+			block.Successors = append(block.Successors, Edge{Block: succ.Index, Kind: edgeKind(b, i)})
+		}
+		for _, pred := range b.Preds {
+			block.Predecessors = append(block.Predecessors, pred.Index)
+		}
+		for _, instr := range b.Instrs {
+			block.Instructions = append(block.Instructions, newInstruction(fn, instr))
+		}
+		g.Blocks = append(g.Blocks, block)
+	}
+	return g
+}
+
+// edgeKind возвращает вид i-го ребра из блока b
+func edgeKind(b *ssa.BasicBlock, i int) string {
+	if len(b.Instrs) == 0 {
+		return ""
+	}
+	if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.If); ok {
+		if i == 0 {
+			return "true"
+		}
+		return "false"
+	}
+	return ""
+}
+
+func newInstruction(fn *ssa.Function, instr ssa.Instruction) Instruction {
+	text := instr.String()
+	if v, ok := instr.(ssa.Value); ok && v.Name() != "" {
+		text = v.Name() + " = " + text
+	}
+	res := Instruction{Text: text, Op: strings.TrimPrefix(fmt.Sprintf("%T", instr), "*ssa.")}
+	if pos := instr.Pos(); pos.IsValid() {
+		p := fn.Prog.Fset.Position(pos)
+		res.Pos = &Position{File: p.Filename, Line: p.Line, Column: p.Column}
+	}
+	return res
+}
+
+// WriteJSON выводит граф в JSON с отступами
+func (g *Graph) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(g)
+}
+
+// WriteDOT выводит граф в формате Graphviz DOT: блоки - узлы с инструкциями,
+// рёбра переходов по условию подписаны true/false
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %q {\n\tnode [shape=box, fontname=\"monospace\"];\n", g.Function)
+	for _, b := range g.Blocks {
+		lines := []string{fmt.Sprintf("%d: %s", b.Index, b.Comment)}
+		for _, instr := range b.Instructions {
+			lines = append(lines, instr.Text)
+		}
+		var label strings.Builder
+		for _, line := range lines {
+			label.WriteString(dotEscape(line) + "\\l")
+		}
+		fmt.Fprintf(&sb, "\tb%d [label=\"%s\"];\n", b.Index, label.String())
+	}
+	for _, b := range g.Blocks {
+		for _, e := range b.Successors {
+			if e.Kind == "" {
+				fmt.Fprintf(&sb, "\tb%d -> b%d;\n", b.Index, e.Block)
+				continue
+			}
+			fmt.Fprintf(&sb, "\tb%d -> b%d [label=%q];\n", b.Index, e.Block, e.Kind)
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// dotEscape экранирует текст для подписи узла Graphviz
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
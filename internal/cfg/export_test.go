@@ -0,0 +1,89 @@
+package cfg
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"symbolic-execution-course/internal/ssa"
+
+	xssa "golang.org/x/tools/go/ssa"
+)
+
+var update = flag.Bool("update", false, "перезаписать эталонные файлы testdata")
+
+// exampleFunc загружает SSA функцию из homework1/examples/test_functions.go
+func exampleFunc(t *testing.T, name string) *xssa.Function {
+	t.Helper()
+	source, err := os.ReadFile("../../homework1/examples/test_functions.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := ssa.NewBuilder().ParseAndBuildSSA(string(source), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+// examples строит граф функции из homework1/examples/test_functions.go
+func examples(t *testing.T, name string) *Graph {
+	t.Helper()
+	return New(exampleFunc(t, name))
+}
+
+func TestExportGolden(t *testing.T) {
+	for _, name := range []string{"simpleIf", "whileLoop", "withGoto"} {
+		g := examples(t, name)
+		for ext, write := range map[string]func(*bytes.Buffer) error{
+			".json": func(b *bytes.Buffer) error { return g.WriteJSON(b) },
+			".dot":  func(b *bytes.Buffer) error { return g.WriteDOT(b) },
+		} {
+			var buf bytes.Buffer
+			if err := write(&buf); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", name+ext)
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != string(want) {
+				t.Errorf("%s differs from %s:\n%s", name, golden, buf.String())
+			}
+		}
+	}
+}
+
+func TestConditionalEdges(t *testing.T) {
+	g := examples(t, "nestedIf")
+	var conditional int
+	for _, b := range g.Blocks {
+		if len(b.Successors) != 2 {
+			continue
+		}
+		conditional++
+		if b.Successors[0].Kind != "true" || b.Successors[1].Kind != "false" {
+			t.Errorf("Expected true/false edges from block %d, got %v", b.Index, b.Successors)
+		}
+		if last := b.Instructions[len(b.Instructions)-1]; last.Op != "If" {
+			t.Errorf("Expected If at the end of block %d, got %+v", b.Index, last)
+		}
+		// Сам If позиции не имеет, а вычисление условия - имеет
+		cond := b.Instructions[len(b.Instructions)-2]
+		if cond.Pos == nil || cond.Pos.File != "main.go" || cond.Pos.Line == 0 {
+			t.Errorf("Expected a positioned condition in block %d, got %+v", b.Index, cond)
+		}
+	}
+	if conditional != 2 {
+		t.Errorf("Expected 2 conditional blocks in nestedIf, got %d", conditional)
+	}
+}
@@ -0,0 +1,137 @@
+package cfg
+
+import (
+	"sort"
+
+	"golang.org/x/tools/go/ssa"
+)
+
+// Loop - естественный цикл: заголовок, блоки с обратными рёбрами в заголовок
+// (latches), все блоки тела вместе с заголовком и выходы - блоки вне цикла,
+// в которые ведут рёбра из тела. Индексы блоков упорядочены по возрастанию
+type Loop struct {
+	Header   int
+	Latches  []int
+	Blocks   []int
+	Exits    []int
+	Depth    int // 1 для внешних циклов
+	Parent   *Loop
+	Children []*Loop
+}
+
+// Contains сообщает, принадлежит ли блок телу цикла
+func (l *Loop) Contains(block int) bool {
+	i := sort.SearchInts(l.Blocks, block)
+	return i < len(l.Blocks) && l.Blocks[i] == block
+}
+
+// LoopInfo - естественные циклы функции и глубина вложенности блоков.
+// Циклы, образованные goto без единого заголовка (несводимые), не находятся
+type LoopInfo struct {
+	Loops     []*Loop  // в порядке заголовков; внешние циклы раньше вложенных
+	BackEdges [][2]int // рёбра latch -> header
+	Depth     []int    // число циклов, содержащих блок
+
+	innermost []*Loop
+}
+
+// Loops находит естественные циклы функции fn: ребро b -> h обратное,
+// если h доминирует над b, а тело цикла с заголовком h - блоки, из которых
+// h достижим по обратным рёбрам, не проходя через h
+func Loops(fn *ssa.Function) *LoopInfo {
+	dom := Dominators(fn)
+	n := len(fn.Blocks)
+	info := &LoopInfo{Depth: make([]int, n), innermost: make([]*Loop, n)}
+
+	byHeader := make(map[int]*Loop)
+	for _, b := range fn.Blocks {
+		for _, s := range b.Succs {
+			if !dom.Dominates(s.Index, b.Index) {
+				continue
+			}
+			info.BackEdges = append(info.BackEdges, [2]int{b.Index, s.Index})
+			loop, ok := byHeader[s.Index]
+			if !ok {
+				loop = &Loop{Header: s.Index}
+				byHeader[s.Index] = loop
+				info.Loops = append(info.Loops, loop)
+			}
+			loop.Latches = append(loop.Latches, b.Index)
+		}
+	}
+
+	for _, loop := range info.Loops {
+		body := map[int]bool{loop.Header: true}
+		stack := append([]int{}, loop.Latches...)
+		for len(stack) > 0 {
+			b := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if body[b] {
+				continue
+			}
+			body[b] = true
+			for _, p := range fn.Blocks[b].Preds {
+				stack = append(stack, p.Index)
+			}
+		}
+		exits := make(map[int]bool)
+		for b := range body {
+			loop.Blocks = append(loop.Blocks, b)
+			for _, s := range fn.Blocks[b].Succs {
+				if !body[s.Index] {
+					exits[s.Index] = true
+				}
+			}
+		}
+		for b := range exits {
+			loop.Exits = append(loop.Exits, b)
+		}
+		sort.Ints(loop.Blocks)
+		sort.Ints(loop.Exits)
+		sort.Ints(loop.Latches)
+	}
+
+	// Внешние циклы содержат больше блоков: после сортировки по размеру родитель
+	// вложенного цикла - последний из обработанных циклов, содержащий его заголовок
+	sort.Slice(info.Loops, func(i, j int) bool {
+		if len(info.Loops[i].Blocks) != len(info.Loops[j].Blocks) {
+			return len(info.Loops[i].Blocks) > len(info.Loops[j].Blocks)
+		}
+		return info.Loops[i].Header < info.Loops[j].Header
+	})
+	for i, loop := range info.Loops {
+		for j := i - 1; j >= 0; j-- {
+			if info.Loops[j].Contains(loop.Header) {
+				loop.Parent = info.Loops[j]
+				break
+			}
+		}
+		loop.Depth = 1
+		if loop.Parent != nil {
+			loop.Depth = loop.Parent.Depth + 1
+			loop.Parent.Children = append(loop.Parent.Children, loop)
+		}
+		for _, b := range loop.Blocks {
+			info.Depth[b]++
+			info.innermost[b] = loop
+		}
+	}
+	sort.SliceStable(info.Loops, func(i, j int) bool {
+		if info.Loops[i].Depth != info.Loops[j].Depth {
+			return info.Loops[i].Depth < info.Loops[j].Depth
+		}
+		return info.Loops[i].Header < info.Loops[j].Header
+	})
+	return info
+}
+
+// LoopOf возвращает самый вложенный цикл, содержащий блок, или nil
+func (li *LoopInfo) LoopOf(block int) *Loop {
+	return li.innermost[block]
+}
+
+// IsHeader сообщает, является ли блок заголовком цикла
+func (li *LoopInfo) IsHeader(block int) bool {
+	loop := li.innermost[block]
+	return loop != nil && loop.Header == block
+}
@@ -0,0 +1,97 @@
+package cfg
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDominatorTrees(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		idom     []int
+		postIdom []int
+	}{
+		// 0 entry -> 1 for.loop -> {2 for.body -> 4 for.loop -> {5 -> 4, 6 -> 1}, 3 for.done}
+		{name: "nestedLoops", idom: []int{-1, 0, 1, 1, 2, 4, 4}, postIdom: []int{1, 3, 4, -1, 6, 4, 1}},
+		// 0 entry -> 3 for.loop -> {1 for.body -> 3, 2 for.done}
+		{name: "whileLoop", idom: []int{-1, 3, 3, 0}, postIdom: []int{3, 3, -1, 2}},
+		// 0 entry -> {1 big, 2} -> 3 end
+		{name: "withGoto", idom: []int{-1, 0, 0, 0}, postIdom: []int{3, 3, 3, -1}},
+	} {
+		fn := exampleFunc(t, tc.name)
+		dom := Dominators(fn)
+		if !reflect.DeepEqual(dom.Idom, tc.idom) {
+			t.Errorf("%s: expected dominators %v, got %v", tc.name, tc.idom, dom.Idom)
+		}
+		for _, b := range fn.Blocks {
+			if b.Idom() != nil && b.Idom().Index != dom.Idom[b.Index] {
+				t.Errorf("%s: block %d idom differs from go/ssa: %d", tc.name, b.Index, b.Idom().Index)
+			}
+		}
+		post := PostDominators(fn)
+		if !reflect.DeepEqual(post.Idom, tc.postIdom) {
+			t.Errorf("%s: expected post-dominators %v, got %v", tc.name, tc.postIdom, post.Idom)
+		}
+		last := len(fn.Blocks) - 1
+		if !dom.Dominates(0, last) || dom.Dominates(last, 0) {
+			t.Errorf("%s: expected entry to strictly dominate block %d", tc.name, last)
+		}
+	}
+}
+
+func TestNaturalLoops(t *testing.T) {
+	type loop struct {
+		Header, Depth          int
+		Latches, Blocks, Exits []int
+	}
+	for _, tc := range []struct {
+		name  string
+		loops []loop
+		depth []int
+	}{
+		{
+			name: "nestedLoops",
+			loops: []loop{
+				{Header: 1, Depth: 1, Latches: []int{6}, Blocks: []int{1, 2, 4, 5, 6}, Exits: []int{3}},
+				{Header: 4, Depth: 2, Latches: []int{5}, Blocks: []int{4, 5}, Exits: []int{6}},
+			},
+			depth: []int{0, 1, 1, 0, 2, 2, 1},
+		},
+		{
+			name:  "whileLoop",
+			loops: []loop{{Header: 3, Depth: 1, Latches: []int{1}, Blocks: []int{1, 3}, Exits: []int{2}}},
+			depth: []int{0, 1, 0, 1},
+		},
+		{
+			name:  "withGoto",
+			depth: []int{0, 0, 0, 0},
+		},
+		{
+			// continue и конец тела - два обратных ребра в один заголовок
+			name:  "loopWithBreakContinue",
+			loops: []loop{{Header: 1, Depth: 1, Latches: []int{2, 5}, Blocks: []int{1, 2, 4, 5}, Exits: []int{3}}},
+			depth: []int{0, 1, 1, 0, 1, 1},
+		},
+	} {
+		info := Loops(exampleFunc(t, tc.name))
+		var got []loop
+		for _, l := range info.Loops {
+			got = append(got, loop{Header: l.Header, Depth: l.Depth, Latches: l.Latches, Blocks: l.Blocks, Exits: l.Exits})
+		}
+		if !reflect.DeepEqual(got, tc.loops) {
+			t.Errorf("%s: expected loops %+v, got %+v", tc.name, tc.loops, got)
+		}
+		if !reflect.DeepEqual(info.Depth, tc.depth) {
+			t.Errorf("%s: expected loop depths %v, got %v", tc.name, tc.depth, info.Depth)
+		}
+	}
+
+	info := Loops(exampleFunc(t, "nestedLoops"))
+	inner := info.LoopOf(5)
+	if inner == nil || inner.Header != 4 || inner.Parent == nil || inner.Parent.Header != 1 {
+		t.Errorf("Expected block 5 in the inner loop nested in the outer one, got %+v", inner)
+	}
+	if !info.IsHeader(1) || !info.IsHeader(4) || info.IsHeader(2) {
+		t.Error("Expected blocks 1 and 4 to be the only loop headers")
+	}
+}
@@ -0,0 +1,106 @@
+package memory_test
+
+import (
+	"testing"
+	"time"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// reachable возвращает число паник вида kind, достижимых на пути path
+func reachable(t *testing.T, mem *memory.SymbolicMemory, kind memory.PanicKind, path ...symbolic.SymbolicExpression) int {
+	t.Helper()
+	var n int
+	for _, rp := range mem.Panics() {
+		if rp.Kind == kind && satisfiable(t, mem, mem.PanicQuery(rp, path...)) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestFixedArrayBounds(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// var arr [5]int; for i := 0; i < 5; i++ { arr[i] = i * i }
+	arr := mem.AllocateArray(intTy, symbolic.NewIntConstant(5))
+	for i := int64(0); i < 5; i++ {
+		mem.AssignToArray(arr, symbolic.NewIntConstant(i), symbolic.NewIntConstant(i*i))
+	}
+	if n := reachable(t, mem, memory.IndexOutOfRange); n != 0 {
+		t.Errorf("Expected no out of bounds access in testArrayFixed, got %d", n)
+	}
+
+	mem.GetFromArray(arr, symbolic.NewIntConstant(5), intTy)
+	if n := reachable(t, mem, memory.IndexOutOfRange); n != 1 {
+		t.Errorf("Expected arr[5] to be out of bounds, got %d violations", n)
+	}
+}
+
+func TestSymbolicIndexBounds(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+
+	// func f(arr [5]int, s []int, i int) { arr[i]; s[i] }
+	arr := mem.InputArray("arr", intTy, symbolic.NewIntConstant(5))
+	s := mem.InputSlice("s", intTy)
+	mem.GetFromArray(arr, i, intTy)
+	mem.GetFromSlice(s, i)
+
+	if n := reachable(t, mem, memory.IndexOutOfRange); n != 2 {
+		t.Errorf("Expected both accesses to be out of bounds for some i, got %d", n)
+	}
+	inArray := []symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(0), symbolic.GE),
+		symbolic.NewBinaryOperation(i, mem.ArrayLen(arr), symbolic.LT),
+	}
+	if n := reachable(t, mem, memory.IndexOutOfRange, inArray...); n != 1 {
+		t.Errorf("Expected only the slice access to be unsafe for 0 <= i < 5, got %d", n)
+	}
+	inSlice := append(inArray, symbolic.NewBinaryOperation(i, s.Len, symbolic.LT))
+	if n := reachable(t, mem, memory.IndexOutOfRange, inSlice...); n != 0 {
+		t.Errorf("Expected no violations for i < min(5, len(s)), got %d", n)
+	}
+}
+
+func TestAppendedArrayLength(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// s := make([]int, 2); s = append(s, 1): базовый массив вырос до ёмкости 4
+	s := mem.Append(mem.MakeSlice(intTy, symbolic.NewIntConstant(2), nil), symbolic.NewIntConstant(1))
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(mem.ArrayLen(s.Array), symbolic.NewIntConstant(4), symbolic.NE)) {
+		t.Error("Expected the grown backing array to have length 4")
+	}
+}
+
+func TestManyArrayWritesStayLinear(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// arr := make([]int, 50); for i := 0; i < 50; i++ { arr[i] = i }
+	arr := mem.AllocateArray(intTy, symbolic.NewIntConstant(50))
+	for i := int64(0); i < 50; i++ {
+		mem.AssignToArray(arr, symbolic.NewIntConstant(i), symbolic.NewIntConstant(i))
+	}
+	last := mem.GetFromArray(arr, symbolic.NewIntConstant(49), intTy)
+	if n := len(last.String()); n > 1<<16 {
+		t.Fatalf("Expected expression size to grow linearly with writes, got %d bytes", n)
+	}
+
+	done := make(chan bool)
+	go func() {
+		done <- satisfiable(t, mem, symbolic.NewBinaryOperation(last, symbolic.NewIntConstant(49), symbolic.NE))
+	}()
+	select {
+	case sat := <-done:
+		if sat {
+			t.Error("Expected arr[49] == 49")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Checking 50 array writes took too long")
+	}
+}
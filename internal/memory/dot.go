@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// HeapField - значение поля или ячейки массива объекта кучи. В конкретном графе
+// (см. z3wrapper.ReadHeapGraph) значения модели хранятся только для отображения:
+// в IndexText и ValueText вместо Index и Value
+type HeapField struct {
+	Name      string
+	Index     symbolic.SymbolicExpression // индекс ячейки массива, nil для полей структур
+	Value     symbolic.SymbolicExpression
+	Heap      symbolic.SymbolicExpression // массив кучи, в котором хранится поле
+	IndexText string                      // индекс ячейки текстом ("*" - остальные ячейки)
+	ValueText string
+}
+
+// Label возвращает подпись поля: имя или "[индекс]"
+func (hf HeapField) Label() string {
+	switch {
+	case hf.IndexText != "":
+		return hf.Name + "[" + hf.IndexText + "]"
+	case hf.Index != nil:
+		return hf.Name + "[" + hf.Index.String() + "]"
+	}
+	return hf.Name
+}
+
+// ValueString возвращает подпись значения поля
+func (hf HeapField) ValueString() string {
+	if hf.ValueText != "" {
+		return hf.ValueText
+	}
+	return hf.Value.String()
+}
+
+// HeapNode - объект кучи. Адрес может быть символьным, тогда Aliases содержит
+// ограничения, от которых зависит, с какими объектами он может совпадать.
+// В конкретном графе Addr - nil, а адрес из модели хранится текстом в AddrText
+type HeapNode struct {
+	ID       string
+	Type     string
+	Addr     symbolic.SymbolicExpression
+	AddrText string
+	Fields   []HeapField
+	Aliases  []symbolic.SymbolicExpression
+}
+
+// AddrString возвращает подпись адреса объекта
+func (n *HeapNode) AddrString() string {
+	if n.AddrText != "" {
+		return n.AddrText
+	}
+	return n.Addr.String()
+}
+
+// HeapEdge - указатель из поля объекта From на объект с адресом Target
+// (nil в конкретном графе)
+type HeapEdge struct {
+	From   string
+	To     string
+	Field  HeapField
+	Target symbolic.SymbolicExpression
+}
+
+// HeapGraph - граф кучи состояния: объекты и указатели между ними
+type HeapGraph struct {
+	Nodes []*HeapNode
+	Edges []HeapEdge
+}
+
+// HeapGraph строит граф кучи по записям в массивы полей. Записи по одному и тому же
+// выражению адреса показываются один раз (последняя), записи по разным выражениям,
+// которые могут совпасть, - все
+func (mem *SymbolicMemory) HeapGraph() *HeapGraph {
+	g := &HeapGraph{}
+	nodes := make(map[exprID]*HeapNode)
+	node := func(addr symbolic.SymbolicExpression, typeName string) *HeapNode {
+		id := idOf(addr)
+		if n, ok := nodes[id]; ok {
+			if n.Type == "" {
+				n.Type = typeName
+			}
+			return n
+		}
+		n := &HeapNode{ID: "n" + strconv.Itoa(len(nodes)), Type: typeName, Addr: addr}
+		nodes[id] = n
+		g.Nodes = append(g.Nodes, n)
+		return n
+	}
+
+	for _, ref := range mem.Inputs {
+		node(ref.Addr, ref.StructName)
+	}
+	keys := make([]string, 0, len(mem.Heap))
+	for key := range mem.Heap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		typeName, path := splitHeapKey(key)
+		name := mem.fieldName(typeName, path)
+		seen := make(map[exprID]bool)
+		for _, w := range heapWrites(mem.Heap[key]) {
+			if seen[idOf(w.Addr)] {
+				continue
+			}
+			seen[idOf(w.Addr)] = true
+			owner := node(w.Addr, typeName)
+			for _, f := range expandValue(name, w.Value) {
+				f.Heap = mem.Heap[key]
+				if f.Value.Type() == symbolic.RefType {
+					target := pointerAddr(f.Value)
+					g.Edges = append(g.Edges, HeapEdge{From: owner.ID, Field: f, Target: target})
+					continue
+				}
+				owner.Fields = append(owner.Fields, f)
+			}
+		}
+	}
+	for i := range g.Edges {
+		g.Edges[i].To = node(g.Edges[i].Target, "").ID
+	}
+
+	constraints := mem.Constraints()
+	for _, n := range g.Nodes {
+		vars := symbolic.FreeVariables(n.Addr)
+		if len(vars) == 0 {
+			continue
+		}
+		for _, c := range constraints {
+			if sharesVariable(vars, symbolic.FreeVariables(c)) {
+				n.Aliases = append(n.Aliases, c)
+			}
+		}
+	}
+	return g
+}
+
+// WriteDOT выводит граф в формате Graphviz DOT
+func (g *HeapGraph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph heap {\n\tnode [shape=record];\n")
+	for _, n := range g.Nodes {
+		var rows []string
+		for _, f := range n.Fields {
+			rows = append(rows, dotEscape(f.Label()+" = "+f.ValueString())+"\\l")
+		}
+		for _, a := range n.Aliases {
+			rows = append(rows, dotEscape(a.String())+"\\l")
+		}
+		header := dotEscape(n.Type + " @ " + n.AddrString())
+		fmt.Fprintf(&sb, "\t%s [label=\"{%s|%s}\"];\n", n.ID, header, strings.Join(rows, ""))
+	}
+	for _, e := range g.Edges {
+		fmt.Fprintf(&sb, "\t%s -> %s [label=\"%s\"];\n", e.From, e.To, dotEscape(e.Field.Label()))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// heapWrite - запись значения по адресу в массив поля
+type heapWrite struct {
+	Addr  symbolic.SymbolicExpression
+	Value symbolic.SymbolicExpression
+}
+
+// heapWrites разворачивает цепочку store в список записей от последней к первой
+func heapWrites(heap symbolic.SymbolicExpression) []heapWrite {
+	var res []heapWrite
+	for {
+		assign, ok := heap.(*symbolic.FieldAssign)
+		if !ok {
+			return res
+		}
+		value := assign.Value
+		if assign.Index != nil {
+			// Запись элемента показываем как store в прежнее содержимое массива
+			contents := symbolic.NewFieldAccess(assign.Heap, assign.Addr, assign.FieldIdx, assign.StructName, *symbolic.InnerTypeOf(assign).InnerTy)
+			value = symbolic.NewStoreOperation(contents, assign.Index, value)
+		}
+		res = append(res, heapWrite{Addr: assign.Addr, Value: value})
+		heap = assign.Heap
+	}
+}
+
+// expandValue раскладывает содержимое массива на ячейки; прочие значения - одно поле
+func expandValue(name string, value symbolic.SymbolicExpression) []HeapField {
+	var res []HeapField
+	seen := make(map[exprID]bool)
+	for {
+		switch v := value.(type) {
+		case *symbolic.BinaryOperation:
+			if v.Operator == symbolic.STORE {
+				if !seen[idOf(v.Right)] {
+					seen[idOf(v.Right)] = true
+					res = append(res, HeapField{Name: name, Index: v.Right, Value: v.Value})
+				}
+				value = v.Left
+				continue
+			}
+		case *symbolic.FieldAccess:
+			// Чтение прежнего содержимого по тому же адресу подменяем записанным значением
+			if prev := lastWrite(v.Heap, v.Addr); prev != nil {
+				value = prev
+				continue
+			}
+		case *symbolic.ArrayConstant:
+			return append(res, HeapField{Name: name, IndexText: "*", Value: v.Value})
+		}
+		if len(res) > 0 && value.Type() == symbolic.ArrayType {
+			// Основа цепочки - исходное содержимое массива
+			return res
+		}
+		return append(res, HeapField{Name: name, Value: value})
+	}
+}
+
+// lastWrite возвращает значение последней записи по адресу addr; записи по другим
+// константным адресам пропускаются, на возможно совпадающем символьном адресе поиск прекращается
+func lastWrite(heap, addr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	for _, w := range heapWrites(heap) {
+		if idOf(w.Addr) == idOf(addr) {
+			return w.Value
+		}
+		_, constW := w.Addr.(*symbolic.IntConstant)
+		_, constA := addr.(*symbolic.IntConstant)
+		if !constW || !constA {
+			return nil
+		}
+	}
+	return nil
+}
+
+// pointerAddr возвращает адрес, хранящийся в значении-указателе
+func pointerAddr(value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if ref, ok := value.(*symbolic.Ref); ok {
+		return ref.Addr
+	}
+	return value
+}
+
+// splitHeapKey разделяет ключ кучи на имя типа и путь индексов поля
+func splitHeapKey(key string) (string, []int) {
+	var path []int
+	for {
+		dot := strings.LastIndex(key, ".")
+		if dot < 0 {
+			return key, path
+		}
+		idx, err := strconv.Atoi(key[dot+1:])
+		if err != nil {
+			return key, path
+		}
+		path = append([]int{idx}, path...)
+		key = key[:dot]
+	}
+}
+
+// fieldName возвращает имя поля по пути (через реестр типов, если он задан)
+func (mem *SymbolicMemory) fieldName(typeName string, path []int) string {
+	if len(path) == 1 && path[0] == arrayLen {
+		return "len"
+	}
+	if mem.types != nil {
+		if layout, ok := mem.types.Lookup(strings.TrimPrefix(typeName, "[]")); ok {
+			var names []string
+			for _, idx := range path {
+				if layout == nil || idx >= len(layout.Fields) {
+					names = nil
+					break
+				}
+				names = append(names, layout.Fields[idx].Name)
+				layout = layout.Fields[idx].Struct
+			}
+			if names != nil {
+				return strings.Join(names, ".")
+			}
+		}
+	}
+	if strings.HasPrefix(typeName, "[]") {
+		return ""
+	}
+	var parts []string
+	for _, idx := range path {
+		parts = append(parts, strconv.Itoa(idx))
+	}
+	return strings.Join(parts, ".")
+}
+
+func sharesVariable(a, b []*symbolic.SymbolicVariable) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x.Name == y.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// dotEscape экранирует текст для подписи record-узла Graphviz
+func dotEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "{", `\{`, "}", `\}`, "|", `\|`, "<", `\<`, ">", `\>`)
+	return r.Replace(s)
+}
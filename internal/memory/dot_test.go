@@ -0,0 +1,49 @@
+package memory_test
+
+import (
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestHeapGraphDOT(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// foo2.a = 5; foo1.a = 2; n := &Node{val: 3}; n.next = n; arr[2] = 9
+	foo1 := mem.InputRef("foo1", symbolic.Object, "Foo")
+	foo2 := mem.InputRef("foo2", symbolic.Object, "Foo")
+	mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
+	mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
+	n := mem.AllocateStruct("Node")
+	mem.AssignField(n, 0, symbolic.NewIntConstant(3))
+	mem.AssignField(n, 1, n)
+	arr := mem.AllocateArray(intTy, symbolic.NewIntConstant(3))
+	mem.AssignToArray(arr, symbolic.NewIntConstant(2), symbolic.NewIntConstant(9))
+
+	g := mem.HeapGraph()
+	if len(g.Nodes) != 4 {
+		t.Fatalf("Expected 4 heap objects, got %d", len(g.Nodes))
+	}
+	if len(g.Edges) != 1 || g.Edges[0].From != g.Edges[0].To {
+		t.Fatalf("Expected a single self loop n.next, got %v", g.Edges)
+	}
+
+	var sb strings.Builder
+	if err := g.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	dot := sb.String()
+	for _, want := range []string{"digraph heap", "0 = 2", "0 = 5", "[2] = 9", "[*] = 0", "len = 3", `label="1"`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("Expected %q in DOT output:\n%s", want, dot)
+		}
+	}
+	for _, node := range g.Nodes {
+		if strings.HasPrefix(node.Addr.String(), "foo") && len(node.Aliases) == 0 {
+			t.Errorf("Expected alias constraints for input address %s", node.Addr)
+		}
+	}
+}
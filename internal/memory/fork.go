@@ -0,0 +1,72 @@
+package memory
+
+import "symbolic-execution-course/internal/symbolic"
+
+// Fork возвращает копию памяти для нового состояния за O(1). Копии разделяют
+// таблицы кучи и ленивой инициализации, и каждая копирует их только при первой
+// записи после Fork. Сами выражения неизменяемы и не копируются никогда
+func (mem *SymbolicMemory) Fork() *SymbolicMemory {
+	mem.heapShared, mem.lazyShared = true, true
+	// Ограничение ёмкости заставляет append в любой из копий выделить новый массив
+	mem.Inputs = mem.Inputs[:len(mem.Inputs):len(mem.Inputs)]
+	mem.axioms = mem.axioms[:len(mem.axioms):len(mem.axioms)]
+	mem.panics = mem.panics[:len(mem.panics):len(mem.panics)]
+	mem.path = mem.path[:len(mem.path):len(mem.path)]
+	mem.objects = mem.objects[:len(mem.objects):len(mem.objects)]
+	mem.copies = mem.copies[:len(mem.copies):len(mem.copies)]
+	child := *mem
+	return &child
+}
+
+// Clone возвращает полную копию памяти, не разделяющую таблицы с исходной
+func (mem *SymbolicMemory) Clone() *SymbolicMemory {
+	res := *mem
+	res.heapShared, res.lazyShared = true, true
+	res.ownHeap()
+	res.ownLazy()
+	res.Inputs = append([]*symbolic.Ref{}, mem.Inputs...)
+	res.axioms = append([]symbolic.SymbolicExpression{}, mem.axioms...)
+	res.panics = append([]RuntimePanic{}, mem.panics...)
+	res.path = append([]symbolic.SymbolicExpression{}, mem.path...)
+	res.objects = append([]*symbolic.Ref{}, mem.objects...)
+	res.copies = append([]arrayCopy{}, mem.copies...)
+	return &res
+}
+
+// ownHeap готовит таблицу кучи к записи: разделяемая после Fork таблица копируется
+func (mem *SymbolicMemory) ownHeap() {
+	if !mem.heapShared {
+		return
+	}
+	heap := make(map[string]symbolic.SymbolicExpression, len(mem.Heap))
+	for k, v := range mem.Heap {
+		heap[k] = v
+	}
+	mem.Heap, mem.heapShared = heap, false
+}
+
+// ownLazy готовит к записи таблицы ленивой инициализации
+func (mem *SymbolicMemory) ownLazy() {
+	if !mem.lazyShared {
+		return
+	}
+	lazyDepth := make(map[exprID]int, len(mem.lazyDepth))
+	for k, v := range mem.lazyDepth {
+		lazyDepth[k] = v
+	}
+	lazyObjects := make(map[string][]symbolic.SymbolicExpression, len(mem.lazyObjects))
+	for k, v := range mem.lazyObjects {
+		lazyObjects[k] = v[:len(v):len(v)]
+	}
+	lazyInitialized := make(map[fieldID]bool, len(mem.lazyInitialized))
+	for k, v := range mem.lazyInitialized {
+		lazyInitialized[k] = v
+	}
+	lazySlots := make(map[string][]lazySlot, len(mem.lazySlots))
+	for k, v := range mem.lazySlots {
+		lazySlots[k] = v[:len(v):len(v)]
+	}
+	mem.lazyDepth, mem.lazyObjects, mem.lazyInitialized = lazyDepth, lazyObjects, lazyInitialized
+	mem.lazySlots = lazySlots
+	mem.lazyShared = false
+}
@@ -0,0 +1,70 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestForkIsolatesWrites(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	p := mem.AllocateStruct("Person")
+	mem.AssignField(p, 0, symbolic.NewIntConstant(30))
+	s := mem.MakeSlice(intTy, symbolic.NewIntConstant(1), symbolic.NewIntConstant(4))
+
+	child := mem.Fork()
+	mem.AssignField(p, 0, symbolic.NewIntConstant(31))
+	child.AssignField(p, 0, symbolic.NewIntConstant(40))
+	mem.GetFromSlice(mem.Append(s, symbolic.NewIntConstant(1)), symbolic.NewIntConstant(1))
+	child.GetFromSlice(child.Append(s, symbolic.NewIntConstant(2)), symbolic.NewIntConstant(1))
+
+	age := func(m *memory.SymbolicMemory, want int64) {
+		cond := symbolic.NewBinaryOperation(m.GetFieldValue(p, 0, intTy), symbolic.NewIntConstant(want), symbolic.NE)
+		if satisfiable(t, m, cond) {
+			t.Errorf("Expected p.Age == %d", want)
+		}
+	}
+	age(mem, 31)
+	age(child, 40)
+	if len(mem.Panics()) != len(child.Panics()) {
+		t.Errorf("Expected independent panic lists of equal length, got %d and %d", len(mem.Panics()), len(child.Panics()))
+	}
+	if mem.Panics()[len(mem.Panics())-1].Cond == child.Panics()[len(child.Panics())-1].Cond {
+		t.Error("Expected forked states not to share appended panics")
+	}
+}
+
+// bigMemory строит память с лениво инициализированным списком из size узлов
+func bigMemory(size int) *memory.SymbolicMemory {
+	mem := memory.NewSymbolicMemory()
+	mem.SetLazyInitDepth(size)
+	node := mem.InputPointer("p", "Node")
+	for i := 0; i < size; i++ {
+		mem.AssignField(node, 0, symbolic.NewIntConstant(int64(i)))
+		node = mem.LoadPointer(node, 1, "Node")
+	}
+	return mem
+}
+
+func BenchmarkFork(b *testing.B) {
+	mem := bigMemory(1000)
+	p := mem.AllocateStruct("Node")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		child := mem.Fork()
+		// Типичное ветвление: запись в каждое из состояний
+		child.AssignField(p, 0, symbolic.NewIntConstant(1))
+	}
+}
+
+func BenchmarkClone(b *testing.B) {
+	mem := bigMemory(1000)
+	p := mem.AllocateStruct("Node")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		child := mem.Clone()
+		child.AssignField(p, 0, symbolic.NewIntConstant(1))
+	}
+}
@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"strconv"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// DefaultLazyInitDepth - глубина ленивой инициализации по умолчанию
+const DefaultLazyInitDepth = 3
+
+// SetLazyInitDepth задаёт, через сколько разыменований от параметров ещё могут
+// появляться новые входные объекты. Глубже указатель может быть только nil или
+// ссылкой на уже созданный объект, что ограничивает рекурсивные типы
+func (mem *SymbolicMemory) SetLazyInitDepth(depth int) {
+	mem.lazyInitDepth = depth
+}
+
+// HeapInvariants - свойства входных структур данных, которые гарантирует вызывающий.
+// Они ограничивают ленивую инициализацию, чтобы solver не строил входы,
+// которые функции никогда не передадут
+type HeapInvariants struct {
+	Acyclic   bool // ни один входной объект не достижим из самого себя
+	NoSharing bool // на каждый входной объект ведёт не больше одного указателя (лес деревьев)
+	MaxDepth  int  // путь по указателям от параметра не длиннее MaxDepth (0 - без ограничения)
+}
+
+// SetHeapInvariants задаёт инварианты входной кучи. Они применяются к указателям,
+// созданным и прочитанным после вызова, поэтому задаются до создания параметров.
+// Инварианты выражаются аксиомами: ацикличность и глубина - через ранг объектов
+// (массив lazy_rank), который строго растёт вдоль каждого входного указателя
+func (mem *SymbolicMemory) SetHeapInvariants(inv HeapInvariants) {
+	mem.invariants = inv
+}
+
+// lazySlot - лениво инициализированное поле-указатель: исходное значение Value
+// поля объекта Obj, хранящегося в массиве кучи Key
+type lazySlot struct {
+	Key   string
+	Obj   symbolic.SymbolicExpression
+	Value symbolic.SymbolicExpression
+}
+
+// InputPointer создаёт указатель-параметр name на объект типа typeName.
+// Указатель может быть nil, указывать на новый объект или совпадать
+// с другим параметром - выбор остаётся solver'у
+func (mem *SymbolicMemory) InputPointer(name string, typeName string) *symbolic.Ref {
+	ref := &symbolic.Ref{Addr: symbolic.NewSymbolicVariable(name, symbolic.IntType), MemTy: symbolic.Object, StructName: typeName}
+	mem.ownLazy()
+	mem.axioms = append(mem.axioms, symbolic.NewBinaryOperation(ref.Addr, symbolic.NewIntConstant(symbolic.NilAddr), symbolic.LE))
+	if mem.invariants.NoSharing {
+		for _, root := range mem.roots(typeName) {
+			mem.axioms = append(mem.axioms, implies(notNil(ref.Addr), symbolic.NewBinaryOperation(ref.Addr, root, symbolic.NE)))
+		}
+	}
+	mem.bindRank(ref.Addr)
+	mem.lazyDepth[idOf(ref.Addr)] = 0
+	mem.lazyObjects[typeName] = append(mem.lazyObjects[typeName], ref.Addr)
+	return ref
+}
+
+// LoadPointer читает поле-указатель fieldIdx объекта ref, указывающее на объект
+// типа typeName. Если ref - входной объект, при первом чтении поля его исходное
+// значение инициализируется лениво: nil, один из уже созданных входных объектов
+// того же типа или (пока не достигнута глубина) новый объект с адресом,
+// отличным от всех существующих
+func (mem *SymbolicMemory) LoadPointer(ref *symbolic.Ref, fieldIdx int, typeName string) *symbolic.Ref {
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	res := &symbolic.Ref{Addr: mem.GetFieldValue(ref, fieldIdx, intTy), MemTy: symbolic.Object, StructName: typeName}
+
+	depth, ok := mem.lazyDepth[idOf(ref.Addr)]
+	if !ok {
+		return res
+	}
+	mem.ownLazy()
+	mem.lazyDepth[idOf(res.Addr)] = depth + 1
+
+	field := fieldID{Addr: idOf(ref.Addr), Field: fieldIdx}
+	if mem.lazyInitialized[field] {
+		return res
+	}
+	mem.lazyInitialized[field] = true
+
+	key := HeapKey(ref.StructName, fieldIdx)
+	initial := symbolic.NewFieldAccess(symbolic.NewSymbolicVariableArray("heap_"+key, intTy), ref.Addr, fieldIdx, ref.StructName, intTy)
+	existing := mem.lazyObjects[typeName]
+
+	cases := []symbolic.SymbolicExpression{symbolic.NewBinaryOperation(initial, symbolic.NewIntConstant(symbolic.NilAddr), symbolic.EQ)}
+	for _, addr := range existing {
+		cases = append(cases, symbolic.NewBinaryOperation(initial, addr, symbolic.EQ))
+	}
+	limit := mem.lazyInitDepth
+	if mem.invariants.MaxDepth > 0 {
+		limit = min(limit, mem.invariants.MaxDepth)
+	}
+	if depth < limit {
+		fresh := symbolic.NewSymbolicVariable("lazy_"+typeName+"_"+strconv.Itoa(mem.lazyCnt), symbolic.IntType)
+		mem.lazyCnt++
+		isFresh := []symbolic.SymbolicExpression{
+			symbolic.NewBinaryOperation(initial, fresh, symbolic.EQ),
+			symbolic.NewBinaryOperation(fresh, symbolic.NewIntConstant(0), symbolic.LT),
+		}
+		for _, addr := range existing {
+			isFresh = append(isFresh, symbolic.NewBinaryOperation(fresh, addr, symbolic.NE))
+		}
+		cases = append(cases, symbolic.NewLogicalOperation(isFresh, symbolic.AND))
+		mem.lazyDepth[idOf(fresh)] = depth + 1
+		mem.lazyObjects[typeName] = append(existing, fresh)
+		mem.bindRank(fresh)
+	} else if mem.invariants.MaxDepth > 0 && depth >= mem.invariants.MaxDepth {
+		// Глубже MaxDepth объектов нет: указатель может быть только nil
+		cases = cases[:1]
+	}
+	mem.axioms = append(mem.axioms, symbolic.NewLogicalOperation(cases, symbolic.OR))
+	mem.constrainSlot(lazySlot{Key: key, Obj: ref.Addr, Value: initial}, typeName)
+	return res
+}
+
+// constrainSlot добавляет аксиомы инвариантов для исходного значения поля-указателя
+func (mem *SymbolicMemory) constrainSlot(slot lazySlot, typeName string) {
+	inv := mem.invariants
+	if inv.Acyclic || inv.MaxDepth > 0 {
+		mem.axioms = append(mem.axioms, implies(notNil(slot.Value),
+			symbolic.NewBinaryOperation(rank(slot.Value), rank(slot.Obj), symbolic.GT)))
+	}
+	if inv.NoSharing {
+		for _, root := range mem.roots(typeName) {
+			mem.axioms = append(mem.axioms, implies(notNil(slot.Value), symbolic.NewBinaryOperation(slot.Value, root, symbolic.NE)))
+		}
+		for _, other := range mem.lazySlots[typeName] {
+			differ := notNil(slot.Value)
+			if other.Key == slot.Key {
+				// Поля одного массива совпадают, если совпадают объекты
+				differ = and(differ, symbolic.NewBinaryOperation(slot.Obj, other.Obj, symbolic.NE))
+			}
+			mem.axioms = append(mem.axioms, implies(differ, symbolic.NewBinaryOperation(slot.Value, other.Value, symbolic.NE)))
+		}
+	}
+	mem.lazySlots[typeName] = append(mem.lazySlots[typeName], slot)
+}
+
+// bindRank ограничивает ранг нового входного объекта глубиной MaxDepth
+func (mem *SymbolicMemory) bindRank(addr symbolic.SymbolicExpression) {
+	if mem.invariants.MaxDepth <= 0 {
+		return
+	}
+	r := rank(addr)
+	mem.axioms = append(mem.axioms, implies(notNil(addr), symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(r, symbolic.NewIntConstant(0), symbolic.GE),
+		symbolic.NewBinaryOperation(r, symbolic.NewIntConstant(int64(mem.invariants.MaxDepth)), symbolic.LE),
+	}, symbolic.AND)))
+}
+
+// roots возвращает адреса параметров типа typeName
+func (mem *SymbolicMemory) roots(typeName string) []symbolic.SymbolicExpression {
+	var res []symbolic.SymbolicExpression
+	for _, addr := range mem.lazyObjects[typeName] {
+		if mem.lazyDepth[idOf(addr)] == 0 {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
+
+// rank возвращает ранг входного объекта: функцию от адреса, поэтому
+// совпадающие адреса имеют один ранг
+func rank(addr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	ranks := symbolic.NewSymbolicVariableArray("lazy_rank", symbolic.InnerType{ExprTy: symbolic.IntType})
+	return symbolic.NewBinaryOperation(ranks, addr, symbolic.SELECT)
+}
+
+func notNil(addr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewBinaryOperation(addr, symbolic.NewIntConstant(symbolic.NilAddr), symbolic.NE)
+}
+
+// containsSlot проверяет, есть ли среди slots поле того же объекта:
+// исходное значение поля определяется массивом кучи и адресом объекта
+func containsSlot(slots []lazySlot, slot lazySlot) bool {
+	for _, s := range slots {
+		if s.Key == slot.Key && idOf(s.Obj) == idOf(slot.Obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkNil записывает возможное разыменование nil для входного указателя
+func (mem *SymbolicMemory) checkNil(ref *symbolic.Ref) {
+	if _, ok := mem.lazyDepth[idOf(ref.Addr)]; ok {
+		mem.mayPanic(NilDereference, symbolic.NewIsNil(ref))
+	}
+}
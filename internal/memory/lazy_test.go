@@ -0,0 +1,126 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// type Node struct {
+// 	val  int
+// 	next *Node
+// }
+
+func TestLazyInitCases(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zero := symbolic.NewIntConstant(0)
+
+	// func f(p *Node) int { return p.next.val }
+	p := mem.InputPointer("p", "Node")
+	next := mem.LoadPointer(p, 1, "Node")
+	mem.GetFieldValue(next, 0, symbolic.InnerType{ExprTy: symbolic.IntType})
+
+	isNil := symbolic.NewBinaryOperation(p.Addr, zero, symbolic.EQ)
+	if !satisfiable(t, mem, isNil) {
+		t.Error("Expected nil input pointer to be feasible")
+	}
+	if !satisfiable(t, mem, symbolic.NewBinaryOperation(next.Addr, p.Addr, symbolic.EQ), symbolic.NewUnaryOperation(symbolic.UN_NOT, isNil)) {
+		t.Error("Expected p.next == p to be feasible")
+	}
+	if !satisfiable(t, mem, symbolic.NewBinaryOperation(next.Addr, p.Addr, symbolic.NE), symbolic.NewBinaryOperation(next.Addr, zero, symbolic.NE)) {
+		t.Error("Expected fresh p.next to be feasible")
+	}
+
+	var nilDerefs int
+	for _, panic := range mem.Panics() {
+		if panic.Kind == memory.NilDereference && satisfiable(t, mem, panic.Cond) {
+			nilDerefs++
+		}
+	}
+	if nilDerefs != 2 {
+		t.Errorf("Expected feasible nil dereferences of p and p.next, got %d", nilDerefs)
+	}
+}
+
+func TestLazyInitDepthBound(t *testing.T) {
+	for _, tc := range []struct {
+		depth int
+		fresh bool
+	}{
+		{depth: 1, fresh: false},
+		{depth: 2, fresh: true},
+	} {
+		mem := memory.NewSymbolicMemory()
+		mem.SetLazyInitDepth(tc.depth)
+		zero := symbolic.NewIntConstant(0)
+
+		// p.next.next - третий объект списка
+		p := mem.InputPointer("p", "Node")
+		second := mem.LoadPointer(p, 1, "Node")
+		third := mem.LoadPointer(second, 1, "Node")
+
+		// Разыменования p и p.next не паникуют
+		distinct := []symbolic.SymbolicExpression{
+			symbolic.NewBinaryOperation(p.Addr, zero, symbolic.NE),
+			symbolic.NewBinaryOperation(second.Addr, zero, symbolic.NE),
+		}
+		for _, addr := range []symbolic.SymbolicExpression{zero, p.Addr, second.Addr} {
+			distinct = append(distinct, symbolic.NewBinaryOperation(third.Addr, addr, symbolic.NE))
+		}
+		if got := satisfiable(t, mem, distinct...); got != tc.fresh {
+			t.Errorf("depth %d: expected fresh third node feasibility %v, got %v", tc.depth, tc.fresh, got)
+		}
+	}
+}
+
+func TestHeapInvariants(t *testing.T) {
+	zero := symbolic.NewIntConstant(0)
+	eq := func(x, y *symbolic.Ref) symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(x.Addr, y.Addr, symbolic.EQ)
+	}
+	nonNil := func(refs ...*symbolic.Ref) []symbolic.SymbolicExpression {
+		var res []symbolic.SymbolicExpression
+		for _, ref := range refs {
+			res = append(res, symbolic.NewBinaryOperation(ref.Addr, zero, symbolic.NE))
+		}
+		return res
+	}
+
+	for _, tc := range []struct {
+		name  string
+		inv   memory.HeapInvariants
+		cycle bool // p.next.next == p
+		cross bool // p.next == q, q.next == p
+		share bool // p.next == q.next
+		deep  bool // p.next.next != nil
+	}{
+		{name: "none", cycle: true, cross: true, share: true, deep: true},
+		{name: "acyclic", inv: memory.HeapInvariants{Acyclic: true}, share: true, deep: true},
+		{name: "no sharing", inv: memory.HeapInvariants{NoSharing: true}, deep: true},
+		{name: "max depth", inv: memory.HeapInvariants{MaxDepth: 1}, share: true},
+	} {
+		mem := memory.NewSymbolicMemory()
+		mem.SetHeapInvariants(tc.inv)
+
+		// func f(p, q *Node) { p.next.next; q.next }
+		p := mem.InputPointer("p", "Node")
+		q := mem.InputPointer("q", "Node")
+		pNext := mem.LoadPointer(p, 1, "Node")
+		pNextNext := mem.LoadPointer(pNext, 1, "Node")
+		qNext := mem.LoadPointer(q, 1, "Node")
+
+		if got := satisfiable(t, mem, append(nonNil(p, pNext), eq(pNextNext, p))...); got != tc.cycle {
+			t.Errorf("%s: expected cycle p -> p.next -> p feasibility %v, got %v", tc.name, tc.cycle, got)
+		}
+		if got := satisfiable(t, mem, append(nonNil(p, q), eq(pNext, q), eq(qNext, p))...); got != tc.cross {
+			t.Errorf("%s: expected cycle p -> q -> p feasibility %v, got %v", tc.name, tc.cross, got)
+		}
+		if got := satisfiable(t, mem, append(nonNil(p, q, pNext), eq(pNext, qNext), symbolic.NewUnaryOperation(symbolic.UN_NOT, eq(p, q)))...); got != tc.share {
+			t.Errorf("%s: expected shared tail feasibility %v, got %v", tc.name, tc.share, got)
+		}
+		if got := satisfiable(t, mem, nonNil(p, pNext, pNextNext)...); got != tc.deep {
+			t.Errorf("%s: expected list of three nodes feasibility %v, got %v", tc.name, tc.deep, got)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+import "symbolic-execution-course/internal/symbolic"
+
+// Поля объекта-мапы в куче
+const (
+	mapValues   = iota // SMT массив "ключ -> значение"
+	mapPresence        // SMT массив "ключ -> есть ли ключ в мапе"
+	mapLen             // число ключей
+)
+
+// MapTypeName возвращает имя типа мапы, под которым хранятся её массивы в куче
+func MapTypeName(keyTy, valueTy symbolic.InnerType) string {
+	return "map[" + keyTy.String() + "]" + valueTy.String()
+}
+
+// MakeMap моделирует make(map[K]V): пустая мапа по новому адресу.
+// Ключи представляются целыми числами, поэтому K - int или ссылка
+func (mem *SymbolicMemory) MakeMap(keyTy, valueTy symbolic.InnerType) *symbolic.Ref {
+	checkMapKey(keyTy)
+	ref := mem.allocate(symbolic.Map, MapTypeName(keyTy, valueTy))
+	if zero := ZeroValue(valueTy); zero != nil {
+		mem.assign(ref, mapValues, symbolic.NewArrayConstant(zero))
+	}
+	mem.assign(ref, mapPresence, symbolic.NewArrayConstant(symbolic.NewBoolConstant(false)))
+	mem.assign(ref, mapLen, symbolic.NewIntConstant(0))
+	return ref
+}
+
+// NilMap возвращает nil-мапу типа map[K]V: чтение из неё даёт нулевые значения,
+// запись паникует
+func (mem *SymbolicMemory) NilMap(keyTy, valueTy symbolic.InnerType) *symbolic.Ref {
+	checkMapKey(keyTy)
+	ref := symbolic.NewNilRef(MapTypeName(keyTy, valueTy))
+	ref.MemTy = symbolic.Map
+	return ref
+}
+
+// InputMap создаёт мапу-параметр name с произвольным содержимым и длиной.
+// Параметр может быть nil
+func (mem *SymbolicMemory) InputMap(name string, keyTy, valueTy symbolic.InnerType) *symbolic.Ref {
+	checkMapKey(keyTy)
+	ref := mem.InputRef(name, symbolic.Map, MapTypeName(keyTy, valueTy))
+	mem.axioms = append(mem.axioms, symbolic.NewBinaryOperation(mem.MapLen(ref), symbolic.NewIntConstant(0), symbolic.GE))
+	return ref
+}
+
+// MapLookup моделирует v, ok := m[key]. Для отсутствующего ключа и nil-мапы
+// v - нулевое значение (если тип значения его имеет), ok - false
+func (mem *SymbolicMemory) MapLookup(m *symbolic.Ref, key symbolic.SymbolicExpression, valueTy symbolic.InnerType) (symbolic.SymbolicExpression, symbolic.SymbolicExpression) {
+	ok := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewUnaryOperation(symbolic.UN_NOT, symbolic.NewIsNil(m)),
+		symbolic.NewBinaryOperation(mem.mapArray(m, mapPresence, symbolic.InnerType{ExprTy: symbolic.BoolType}), key, symbolic.SELECT),
+	}, symbolic.AND)
+	value := symbolic.SymbolicExpression(symbolic.NewBinaryOperation(mem.mapArray(m, mapValues, valueTy), key, symbolic.SELECT))
+	if zero := ZeroValue(valueTy); zero != nil {
+		value = symbolic.NewTernaryOperation(ok, value, zero)
+	}
+	return value, ok
+}
+
+// MapAssign моделирует m[key] = value; запись в nil-мапу паникует
+func (mem *SymbolicMemory) MapAssign(m *symbolic.Ref, key, value symbolic.SymbolicExpression) {
+	mem.mayPanic(NilMapWrite, symbolic.NewIsNil(m))
+	present := mem.present(m, key)
+	added := symbolic.NewTernaryOperation(present, symbolic.NewIntConstant(0), symbolic.NewIntConstant(1))
+
+	mem.assignElem(m, []int{mapValues}, key, value)
+	mem.assign(m, mapLen, symbolic.NewBinaryOperation(mem.GetFieldValue(m, mapLen, symbolic.InnerType{ExprTy: symbolic.IntType}), added, symbolic.ADD))
+	mem.setPresence(m, key, true)
+}
+
+// MapDelete моделирует delete(m, key); удаление из nil-мапы ничего не делает
+func (mem *SymbolicMemory) MapDelete(m *symbolic.Ref, key symbolic.SymbolicExpression) {
+	present := mem.present(m, key)
+	removed := symbolic.NewTernaryOperation(present, symbolic.NewIntConstant(1), symbolic.NewIntConstant(0))
+	mem.assign(m, mapLen, symbolic.NewBinaryOperation(mem.GetFieldValue(m, mapLen, symbolic.InnerType{ExprTy: symbolic.IntType}), removed, symbolic.SUB))
+	mem.setPresence(m, key, false)
+}
+
+// MapLen моделирует len(m); длина nil-мапы равна 0
+func (mem *SymbolicMemory) MapLen(m *symbolic.Ref) symbolic.SymbolicExpression {
+	isNil := symbolic.NewIsNil(m)
+	return symbolic.NewTernaryOperation(isNil, symbolic.NewIntConstant(0), mem.GetFieldValue(m, mapLen, symbolic.InnerType{ExprTy: symbolic.IntType}))
+}
+
+// mapArray возвращает текущий массив поля мапы с элементами типа elemTy
+func (mem *SymbolicMemory) mapArray(m *symbolic.Ref, field int, elemTy symbolic.InnerType) symbolic.SymbolicExpression {
+	return mem.get(m, field, symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy})
+}
+
+func (mem *SymbolicMemory) present(m *symbolic.Ref, key symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewBinaryOperation(mem.mapArray(m, mapPresence, symbolic.InnerType{ExprTy: symbolic.BoolType}), key, symbolic.SELECT)
+}
+
+func (mem *SymbolicMemory) setPresence(m *symbolic.Ref, key symbolic.SymbolicExpression, value bool) {
+	mem.assignElem(m, []int{mapPresence}, key, symbolic.NewBoolConstant(value))
+}
+
+func checkMapKey(keyTy symbolic.InnerType) {
+	if keyTy.ExprTy != symbolic.IntType && keyTy.ExprTy != symbolic.RefType {
+		panic("unsupported map key type " + keyTy.String())
+	}
+}
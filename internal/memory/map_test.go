@@ -0,0 +1,89 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestMapOperations(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	k1 := symbolic.NewSymbolicVariable("k1", symbolic.IntType)
+	k2 := symbolic.NewSymbolicVariable("k2", symbolic.IntType)
+	eq := func(a, b symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(a, b, symbolic.EQ)
+	}
+	not := func(e symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+		return symbolic.NewUnaryOperation(symbolic.UN_NOT, e)
+	}
+
+	// m := make(map[int]int); m[k1] = 10; v, ok := m[k2]
+	m := mem.MakeMap(intTy, intTy)
+	mem.MapAssign(m, k1, symbolic.NewIntConstant(10))
+	v, ok := mem.MapLookup(m, k2, intTy)
+
+	if satisfiable(t, mem, eq(k1, k2), not(eq(v, symbolic.NewIntConstant(10)))) {
+		t.Error("Expected m[k2] == 10 when k1 == k2")
+	}
+	if satisfiable(t, mem, not(eq(k1, k2)), ok) {
+		t.Error("Expected missing key when k1 != k2")
+	}
+	if satisfiable(t, mem, not(eq(k1, k2)), not(eq(v, symbolic.NewIntConstant(0)))) {
+		t.Error("Expected zero value for missing key")
+	}
+
+	// m[k2] = 20; delete(m, k1)
+	mem.MapAssign(m, k2, symbolic.NewIntConstant(20))
+	afterAssign := mem.MapLen(m)
+	mem.MapDelete(m, k1)
+	afterDelete := mem.MapLen(m)
+	if satisfiable(t, mem, eq(k1, k2), not(eq(afterAssign, symbolic.NewIntConstant(1)))) {
+		t.Error("Expected len(m) == 1 after assigning the same key twice")
+	}
+	if satisfiable(t, mem, not(eq(k1, k2)), not(eq(afterAssign, symbolic.NewIntConstant(2)))) {
+		t.Error("Expected len(m) == 2 after assigning distinct keys")
+	}
+	if satisfiable(t, mem, not(eq(afterDelete, symbolic.NewIntConstant(0))), eq(k1, k2)) {
+		t.Error("Expected empty map after deleting the only key")
+	}
+
+	for _, p := range mem.Panics() {
+		if satisfiable(t, mem, p.Cond) {
+			t.Errorf("Unexpected feasible panic: %v", p)
+		}
+	}
+}
+
+func TestNilMapWritePanics(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// var m map[int]int; _, ok := m[1]; m[1] = 2
+	m := mem.NilMap(intTy, intTy)
+	_, ok := mem.MapLookup(m, symbolic.NewIntConstant(1), intTy)
+	if satisfiable(t, mem, ok) {
+		t.Error("Expected no keys in nil map")
+	}
+	mem.MapAssign(m, symbolic.NewIntConstant(1), symbolic.NewIntConstant(2))
+
+	panics := mem.Panics()
+	if len(panics) != 1 || panics[0].Kind != memory.NilMapWrite || !satisfiable(t, mem, panics[0].Cond) {
+		t.Errorf("Expected feasible nil map write panic, got %v", panics)
+	}
+}
+
+func TestNilInputMapWritePanics(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// func f(m map[int]int) { m[1] = 2 }
+	m := mem.InputMap("m", intTy, intTy)
+	mem.MapAssign(m, symbolic.NewIntConstant(1), symbolic.NewIntConstant(2))
+
+	panics := mem.Panics()
+	if len(panics) != 1 || panics[0].Kind != memory.NilMapWrite || !satisfiable(t, mem, panics[0].Cond) {
+		t.Errorf("Expected feasible nil map write for a parameter map, got %v", panics)
+	}
+}
@@ -0,0 +1,165 @@
+package memory
+
+import "symbolic-execution-course/internal/symbolic"
+
+// Merge объединяет памяти двух путей, сходящихся в одной точке CFG: a - память
+// пути, на котором выполнено cond, b - пути, на котором оно не выполнено.
+// Различающиеся массивы кучи становятся TernaryOperation(cond, a, b), а аксиомы
+// и паники, появившиеся только на одном из путей, защищаются условием этого пути.
+// Ожидается, что a и b получены через Fork от общей памяти
+func Merge(cond symbolic.SymbolicExpression, a, b *SymbolicMemory) *SymbolicMemory {
+	notCond := symbolic.NewUnaryOperation(symbolic.UN_NOT, cond)
+	res := NewSymbolicMemory()
+	res.AddrCnt = max(a.AddrCnt, b.AddrCnt)
+	res.lazyInitDepth = a.lazyInitDepth
+	res.invariants = a.invariants
+	res.types = a.types
+
+	for key, heapA := range a.Heap {
+		heapB, ok := b.Heap[key]
+		if !ok {
+			heapB = b.initialHeap(key, *symbolic.InnerTypeOf(heapA).InnerTy)
+		}
+		res.Heap[key] = mergeValue(cond, heapA, heapB)
+	}
+	for key, heapB := range b.Heap {
+		if _, ok := a.Heap[key]; !ok {
+			res.Heap[key] = mergeValue(cond, a.initialHeap(key, *symbolic.InnerTypeOf(heapB).InnerTy), heapB)
+		}
+	}
+
+	n := commonPrefix(len(a.axioms), len(b.axioms), func(i int) bool { return a.axioms[i] == b.axioms[i] })
+	res.axioms = append(res.axioms, a.axioms[:n]...)
+	for _, axiom := range a.axioms[n:] {
+		res.axioms = append(res.axioms, implies(cond, axiom))
+	}
+	for _, axiom := range b.axioms[n:] {
+		res.axioms = append(res.axioms, implies(notCond, axiom))
+	}
+
+	n = commonPrefix(len(a.panics), len(b.panics), func(i int) bool { return a.panics[i] == b.panics[i] })
+	res.panics = append(res.panics, a.panics[:n]...)
+	for _, p := range a.panics[n:] {
+		res.panics = append(res.panics, RuntimePanic{Kind: p.Kind, Cond: and(cond, p.Cond), Path: p.Path})
+	}
+	for _, p := range b.panics[n:] {
+		res.panics = append(res.panics, RuntimePanic{Kind: p.Kind, Cond: and(notCond, p.Cond), Path: p.Path})
+	}
+
+	// После слияния путь ограничен только общими для обоих путей условиями
+	n = commonPrefix(len(a.path), len(b.path), func(i int) bool { return a.path[i] == b.path[i] })
+	res.path = append(res.path, a.path[:n]...)
+
+	n = commonPrefix(len(a.Inputs), len(b.Inputs), func(i int) bool { return a.Inputs[i] == b.Inputs[i] })
+	res.Inputs = append(append(res.Inputs, a.Inputs...), b.Inputs[n:]...)
+	n = commonPrefix(len(a.objects), len(b.objects), func(i int) bool { return a.objects[i] == b.objects[i] })
+	res.objects = append(append(res.objects, a.objects...), b.objects[n:]...)
+
+	n = commonPrefix(len(a.copies), len(b.copies), func(i int) bool { return a.copies[i] == b.copies[i] })
+	res.copies = append(res.copies, a.copies[:n]...)
+	for _, c := range a.copies[n:] {
+		c.Guard = guard(cond, c.Guard)
+		res.copies = append(res.copies, c)
+	}
+	for _, c := range b.copies[n:] {
+		c.Guard = guard(notCond, c.Guard)
+		res.copies = append(res.copies, c)
+	}
+
+	for _, m := range []*SymbolicMemory{a, b} {
+		for k, v := range m.lazyDepth {
+			if d, ok := res.lazyDepth[k]; !ok || v < d {
+				res.lazyDepth[k] = v
+			}
+		}
+	}
+	// Аксиомы ленивой инициализации на одном пути защищены его условием, поэтому
+	// поле, инициализированное лишь на одном пути, на другом ничем не ограничено.
+	// Такие поля и созданные для них объекты забываются: при следующем чтении
+	// поле инициализируется заново
+	res.lazyCnt = max(a.lazyCnt, b.lazyCnt)
+	for k := range a.lazyInitialized {
+		if b.lazyInitialized[k] {
+			res.lazyInitialized[k] = true
+		}
+	}
+	for typeName, addrs := range a.lazyObjects {
+		for _, addr := range addrs {
+			if containsExpr(b.lazyObjects[typeName], addr) {
+				res.lazyObjects[typeName] = append(res.lazyObjects[typeName], addr)
+			}
+		}
+	}
+	for typeName, slots := range a.lazySlots {
+		for _, slot := range slots {
+			if containsSlot(b.lazySlots[typeName], slot) {
+				res.lazySlots[typeName] = append(res.lazySlots[typeName], slot)
+			}
+		}
+	}
+	return res
+}
+
+// mergeValue возвращает ITE(cond, x, y) или x, если значения совпадают
+func mergeValue(cond, x, y symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if x == y {
+		return x
+	}
+	return symbolic.NewTernaryOperation(cond, x, y)
+}
+
+func commonPrefix(la, lb int, same func(i int) bool) int {
+	n := 0
+	for n < la && n < lb && same(n) {
+		n++
+	}
+	return n
+}
+
+func containsExpr(exprs []symbolic.SymbolicExpression, expr symbolic.SymbolicExpression) bool {
+	for _, e := range exprs {
+		if idOf(e) == idOf(expr) {
+			return true
+		}
+	}
+	return false
+}
+
+// exprID - ключ выражения в таблицах памяти. Переменные и константы сравниваются
+// по значению, составные выражения - по указателю: их строковое представление
+// может расти экспоненциально от числа записей
+type exprID interface{}
+
+func idOf(e symbolic.SymbolicExpression) exprID {
+	switch v := e.(type) {
+	case *symbolic.SymbolicVariable:
+		return v.Name
+	case *symbolic.IntConstant:
+		return v.Value
+	case *symbolic.BoolConstant:
+		return v.Value
+	}
+	return e
+}
+
+// fieldID - поле объекта по адресу Addr
+type fieldID struct {
+	Addr  exprID
+	Field int
+}
+
+func implies(cond, expr symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{cond, expr}, symbolic.IMPLIES)
+}
+
+// guard добавляет условие пути cond к условию g (nil - условие отсутствует)
+func guard(cond, g symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if g == nil {
+		return cond
+	}
+	return and(cond, g)
+}
+
+func and(x, y symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{x, y}, symbolic.AND)
+}
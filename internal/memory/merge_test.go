@@ -0,0 +1,73 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+func TestMergeUsesTernaryForDifferingCells(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	cond := symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.GT)
+
+	// p := &Person{}; if x > 0 { p.Age = 1; _ = s[x] } else { p.Age = 2 }
+	p := mem.AllocateStruct("Person")
+	mem.AssignField(p, 1, symbolic.NewIntConstant(1001))
+	s := mem.MakeSlice(intTy, symbolic.NewIntConstant(3), nil)
+	thenMem, elseMem := mem, mem.Fork()
+	thenMem.AssignField(p, 0, symbolic.NewIntConstant(1))
+	thenMem.GetFromSlice(s, x)
+	elseMem.AssignField(p, 0, symbolic.NewIntConstant(2))
+
+	merged := memory.Merge(cond, thenMem, elseMem)
+	if merged.Heap["Person.1"] != mem.Heap["Person.1"] {
+		t.Error("Expected unchanged cell to be shared, not merged")
+	}
+
+	age := merged.GetFieldValue(p, 0, intTy)
+	if satisfiable(t, merged, cond, symbolic.NewBinaryOperation(age, symbolic.NewIntConstant(1), symbolic.NE)) {
+		t.Error("Expected p.Age == 1 when x > 0")
+	}
+	if satisfiable(t, merged, symbolic.NewUnaryOperation(symbolic.UN_NOT, cond), symbolic.NewBinaryOperation(age, symbolic.NewIntConstant(2), symbolic.NE)) {
+		t.Error("Expected p.Age == 2 when x <= 0")
+	}
+
+	// Паника индекса возможна только на ветке then и только при x >= 3
+	panics := merged.Panics()
+	last := panics[len(panics)-1]
+	if last.Kind != memory.IndexOutOfRange || !satisfiable(t, merged, last.Cond) {
+		t.Fatalf("Expected feasible index panic from then-branch, got %v", panics)
+	}
+	if satisfiable(t, merged, last.Cond, symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(3), symbolic.LT)) {
+		t.Error("Expected index panic only for x >= 3")
+	}
+}
+
+func TestMergeForgetsOneSidedLazyInit(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	c := symbolic.NewSymbolicVariable("c", symbolic.BoolType)
+	notC := symbolic.NewUnaryOperation(symbolic.UN_NOT, c)
+
+	// func f(p *Node, c bool) { if c { _ = p.next }; q := p.next }
+	p := mem.InputPointer("p", "Node")
+	a, b := mem, mem.Fork()
+	a.LoadPointer(p, 1, "Node")
+	merged := memory.Merge(c, a, b)
+	next := merged.LoadPointer(p, 1, "Node")
+
+	// На пути ¬c поле не было прочитано, но его значение всё равно - входной объект или nil
+	notNil := symbolic.NewBinaryOperation(p, symbolic.NewNilRef("Node"), symbolic.NE)
+	bogus := symbolic.NewBinaryOperation(next.Addr, symbolic.NewIntConstant(42), symbolic.EQ)
+	if satisfiable(t, merged, notC, notNil, bogus) {
+		t.Error("Expected p.next == 42 to be infeasible on the path where p.next was not read")
+	}
+	if satisfiable(t, merged, c, notNil, bogus) {
+		t.Error("Expected p.next == 42 to be infeasible on the path where p.next was read")
+	}
+	if !satisfiable(t, merged, notC, notNil, symbolic.NewBinaryOperation(next.Addr, symbolic.NewIntConstant(0), symbolic.LT)) {
+		t.Error("Expected p.next to be able to point to a new input object")
+	}
+}
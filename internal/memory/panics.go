@@ -0,0 +1,99 @@
+package memory
+
+import "symbolic-execution-course/internal/symbolic"
+
+// PanicKind - вид паники времени исполнения
+type PanicKind int
+
+const (
+	IndexOutOfRange PanicKind = iota
+	SliceBoundsOutOfRange
+	MakeSliceLenOutOfRange
+	NilMapWrite
+	NilDereference
+)
+
+// String возвращает сообщение паники в формате рантайма Go
+func (k PanicKind) String() string {
+	switch k {
+	case IndexOutOfRange:
+		return "index out of range"
+	case SliceBoundsOutOfRange:
+		return "slice bounds out of range"
+	case MakeSliceLenOutOfRange:
+		return "makeslice: len out of range"
+	case NilMapWrite:
+		return "assignment to entry in nil map"
+	case NilDereference:
+		return "invalid memory address or nil pointer dereference"
+	default:
+		return "unknown panic"
+	}
+}
+
+// RuntimePanic - возможная паника операции с памятью: операция паникует,
+// если выполнено Cond. Path - условие пути в момент операции, заданное
+// через AssumePath (nil - путь ничем не ограничен)
+type RuntimePanic struct {
+	Kind PanicKind
+	Cond symbolic.SymbolicExpression
+	Path symbolic.SymbolicExpression
+}
+
+// String возвращает строковое представление паники
+func (rp RuntimePanic) String() string {
+	return rp.Kind.String() + " if " + rp.Cond.String()
+}
+
+// Panics возвращает возможные паники всех выполненных операций в порядке выполнения
+func (mem *SymbolicMemory) Panics() []RuntimePanic {
+	return mem.panics
+}
+
+// AssumePath добавляет условие ветвления cond к условию текущего пути.
+// Паники следующих операций записываются вместе с ним, поэтому проверяются
+// только на том пути, где операция выполнялась
+func (mem *SymbolicMemory) AssumePath(cond symbolic.SymbolicExpression) {
+	mem.path = append(mem.path, cond)
+}
+
+// PanicQuery строит запрос нарушения: паника rp происходит на пути, где она была
+// записана, с дополнительными условиями path при выполнении аксиом кучи.
+// Выполнимость запроса означает достижимую панику, а его модель - входные данные,
+// на которых она происходит
+func (mem *SymbolicMemory) PanicQuery(rp RuntimePanic, path ...symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	operands := append(mem.Constraints(), path...)
+	if rp.Path != nil {
+		operands = append(operands, rp.Path)
+	}
+	return symbolic.NewLogicalOperation(append(operands, rp.Cond), symbolic.AND)
+}
+
+func (mem *SymbolicMemory) mayPanic(kind PanicKind, cond symbolic.SymbolicExpression) {
+	var path symbolic.SymbolicExpression
+	switch len(mem.path) {
+	case 0:
+	case 1:
+		path = mem.path[0]
+	default:
+		path = symbolic.NewLogicalOperation(append([]symbolic.SymbolicExpression{}, mem.path...), symbolic.AND)
+	}
+	mem.panics = append(mem.panics, RuntimePanic{Kind: kind, Cond: cond, Path: path})
+}
+
+// outside строит условие "не выполнено lo <= x < hi"
+func outside(lo, x, hi symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, lo, symbolic.LT),
+		symbolic.NewBinaryOperation(x, hi, symbolic.GE),
+	}, symbolic.OR)
+}
+
+// unordered строит условие "не выполнено b[0] <= b[1] <= ... <= b[n-1]"
+func unordered(bounds ...symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	var operands []symbolic.SymbolicExpression
+	for i := 1; i < len(bounds); i++ {
+		operands = append(operands, symbolic.NewBinaryOperation(bounds[i-1], bounds[i], symbolic.GT))
+	}
+	return symbolic.NewLogicalOperation(operands, symbolic.OR)
+}
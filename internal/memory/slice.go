@@ -0,0 +1,185 @@
+package memory
+
+import "symbolic-execution-course/internal/symbolic"
+
+// Slice - заголовок слайса: ссылка на базовый массив, смещение первого элемента
+// в нём, длина и ёмкость. Заголовок копируется по значению, а массив - нет,
+// поэтому слайсы, полученные друг из друга перенарезкой, видят записи друг друга
+type Slice struct {
+	Array  *symbolic.Ref
+	Offset symbolic.SymbolicExpression
+	Len    symbolic.SymbolicExpression
+	Cap    symbolic.SymbolicExpression
+	ElemTy symbolic.InnerType
+}
+
+// String возвращает строковое представление заголовка слайса
+func (s *Slice) String() string {
+	return s.Array.String() + "[" + s.Offset.String() + ":len " + s.Len.String() + ":cap " + s.Cap.String() + "]"
+}
+
+// MakeSlice моделирует make([]T, length, capacity); capacity может быть nil
+func (mem *SymbolicMemory) MakeSlice(elemTy symbolic.InnerType, length, capacity symbolic.SymbolicExpression) *Slice {
+	if capacity == nil {
+		capacity = length
+	}
+	mem.mayPanic(MakeSliceLenOutOfRange, unordered(symbolic.NewIntConstant(0), length, capacity))
+	return &Slice{
+		Array:  mem.AllocateSlice(elemTy, capacity),
+		Offset: symbolic.NewIntConstant(0),
+		Len:    length,
+		Cap:    capacity,
+		ElemTy: elemTy,
+	}
+}
+
+// InputSlice создаёт слайс-параметр name с символьными длиной и ёмкостью
+// ("<name>_len", "<name>_cap"). Базовые массивы разных параметров могут совпадать
+func (mem *SymbolicMemory) InputSlice(name string, elemTy symbolic.InnerType) *Slice {
+	arrTy := symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy}
+	s := &Slice{
+		Array:  mem.InputRef(name, symbolic.Array, arrTy.String()),
+		Offset: symbolic.NewIntConstant(0),
+		Len:    symbolic.NewSymbolicVariable(name+"_len", symbolic.IntType),
+		Cap:    symbolic.NewSymbolicVariable(name+"_cap", symbolic.IntType),
+		ElemTy: elemTy,
+	}
+	mem.axioms = append(mem.axioms,
+		symbolic.NewBinaryOperation(s.Len, symbolic.NewIntConstant(0), symbolic.GE),
+		symbolic.NewBinaryOperation(s.Len, s.Cap, symbolic.LE))
+	return s
+}
+
+// GetFromSlice читает s[index]
+func (mem *SymbolicMemory) GetFromSlice(s *Slice, index symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	mem.mayPanic(IndexOutOfRange, outside(symbolic.NewIntConstant(0), index, s.Len))
+	return mem.loadElem(s.Array, shift(s.Offset, index), s.ElemTy)
+}
+
+// AssignToSlice записывает s[index] = value
+func (mem *SymbolicMemory) AssignToSlice(s *Slice, index, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	mem.mayPanic(IndexOutOfRange, outside(symbolic.NewIntConstant(0), index, s.Len))
+	return mem.storeElem(s.Array, shift(s.Offset, index), value)
+}
+
+// Reslice моделирует s[low:high:max]; отсутствующие границы передаются как nil
+// и по умолчанию равны 0, len(s) и cap(s) соответственно
+func (mem *SymbolicMemory) Reslice(s *Slice, low, high, max symbolic.SymbolicExpression) *Slice {
+	if low == nil {
+		low = symbolic.NewIntConstant(0)
+	}
+	if high == nil {
+		high = s.Len
+	}
+	if max == nil {
+		max = s.Cap
+	}
+	mem.mayPanic(SliceBoundsOutOfRange, unordered(symbolic.NewIntConstant(0), low, high, max, s.Cap))
+	return &Slice{
+		Array:  s.Array,
+		Offset: shift(s.Offset, low),
+		Len:    symbolic.NewBinaryOperation(high, low, symbolic.SUB),
+		Cap:    symbolic.NewBinaryOperation(max, low, symbolic.SUB),
+		ElemTy: s.ElemTy,
+	}
+}
+
+// shift возвращает offset + index, не порождая сложения с нулём
+func shift(offset, index symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	if c, ok := offset.(*symbolic.IntConstant); ok && c.Value == 0 {
+		return index
+	}
+	if c, ok := index.(*symbolic.IntConstant); ok && c.Value == 0 {
+		return offset
+	}
+	return symbolic.NewBinaryOperation(offset, index, symbolic.ADD)
+}
+
+// Append моделирует append(s, values...). Если len(s)+n <= cap(s), значения
+// записываются в базовый массив s (и становятся видны всем слайсам, которые его
+// делят), иначе выделяется новый массив, в начало которого копируются элементы s,
+// а остальные ячейки нулевые. Выбор кодируется через ITE по условию AppendFits,
+// поэтому ветвление не требуется.
+//
+// Новая ёмкость выбирается как max(2*cap, len+n): правило роста рантайма Go для
+// больших слайсов и округление до классов размеров не моделируются
+func (mem *SymbolicMemory) Append(s *Slice, values ...symbolic.SymbolicExpression) *Slice {
+	n := symbolic.NewIntConstant(int64(len(values)))
+	newLen := symbolic.NewBinaryOperation(s.Len, n, symbolic.ADD)
+	fits := AppendFits(s, len(values))
+
+	fresh := mem.allocate(symbolic.Array, s.Array.StructName)
+	contents := symbolic.NewSymbolicVariableArray("append_"+fresh.Addr.String(), s.ElemTy)
+	mem.copies = append(mem.copies, arrayCopy{
+		Contents: contents,
+		Src:      mem.arrayContents(s.Array, s.ElemTy),
+		From:     s.Offset,
+		Len:      s.Len,
+		Zero:     ZeroValue(s.ElemTy),
+	})
+	mem.assign(fresh, 0, contents)
+
+	doubled := symbolic.NewBinaryOperation(s.Cap, symbolic.NewIntConstant(2), symbolic.MUL)
+	grown := symbolic.NewTernaryOperation(symbolic.NewBinaryOperation(newLen, doubled, symbolic.GT), newLen, doubled)
+	mem.assign(fresh, arrayLen, grown)
+
+	res := &Slice{
+		Array: &symbolic.Ref{
+			Addr:       symbolic.NewTernaryOperation(fits, s.Array.Addr, fresh.Addr),
+			MemTy:      symbolic.Array,
+			StructName: s.Array.StructName,
+		},
+		Offset: symbolic.NewTernaryOperation(fits, s.Offset, symbolic.NewIntConstant(0)),
+		Len:    newLen,
+		Cap:    symbolic.NewTernaryOperation(fits, s.Cap, grown),
+		ElemTy: s.ElemTy,
+	}
+	for i, v := range values {
+		idx := symbolic.NewBinaryOperation(s.Len, symbolic.NewIntConstant(int64(i)), symbolic.ADD)
+		mem.storeElem(res.Array, shift(res.Offset, idx), v)
+	}
+	return res
+}
+
+// arrayCopy - исходное содержимое массива, выделенного в Append:
+// Contents[j] = Src[From+j] при 0 <= j < Len, иначе Zero. Без кванторов это
+// нельзя записать сразу для всех j, поэтому определение добавляется аксиомой
+// для каждого индекса, по которому читается массив (см. defineCopies)
+type arrayCopy struct {
+	Contents symbolic.SymbolicExpression
+	Src      symbolic.SymbolicExpression
+	From     symbolic.SymbolicExpression
+	Len      symbolic.SymbolicExpression
+	Zero     symbolic.SymbolicExpression // nil, если у типа элементов нет нулевого значения
+	Guard    symbolic.SymbolicExpression // условие пути, на котором выполнено копирование (nil - любой)
+}
+
+// defineCopies добавляет определения массивов arrayCopy с элементами типа elemTy
+// в ячейке index: чтение любого массива может оказаться чтением копии
+func (mem *SymbolicMemory) defineCopies(index symbolic.SymbolicExpression, elemTy symbolic.InnerType) {
+	for _, c := range mem.copies {
+		if symbolic.InnerTypeOf(c.Contents).InnerTy.String() != elemTy.String() {
+			continue
+		}
+		cell := symbolic.NewBinaryOperation(c.Contents, index, symbolic.SELECT)
+		src := symbolic.NewBinaryOperation(c.Src, shift(c.From, index), symbolic.SELECT)
+		out := outside(symbolic.NewIntConstant(0), index, c.Len)
+		var def symbolic.SymbolicExpression
+		if c.Zero != nil {
+			def = symbolic.NewBinaryOperation(cell, symbolic.NewTernaryOperation(out, c.Zero, src), symbolic.EQ)
+		} else {
+			def = implies(symbolic.NewUnaryOperation(symbolic.UN_NOT, out), symbolic.NewBinaryOperation(cell, src, symbolic.EQ))
+		}
+		if c.Guard != nil {
+			def = implies(c.Guard, def)
+		}
+		mem.axioms = append(mem.axioms, def)
+	}
+}
+
+// AppendFits возвращает условие, при котором append n элементов к s
+// не перевыделяет базовый массив: len(s)+n <= cap(s)
+func AppendFits(s *Slice, n int) symbolic.SymbolicExpression {
+	newLen := symbolic.NewBinaryOperation(s.Len, symbolic.NewIntConstant(int64(n)), symbolic.ADD)
+	return symbolic.NewBinaryOperation(newLen, s.Cap, symbolic.LE)
+}
@@ -0,0 +1,169 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// satisfiable проверяет выполнимость ограничений вместе с аксиомами памяти
+func satisfiable(t *testing.T, mem *memory.SymbolicMemory, conds ...symbolic.SymbolicExpression) bool {
+	t.Helper()
+	zt := translator.NewZ3Translator(mem)
+	s := z3.NewSolver(zt.GetContext())
+	for _, c := range append(mem.Constraints(), conds...) {
+		z, _ := zt.TranslateExpression(c)
+		s.Assert(z.(z3.Bool))
+	}
+	sat, err := s.Check()
+	if err != nil {
+		t.Fatalf("Unexpected solver error: %v", err)
+	}
+	return sat
+}
+
+func TestResliceSharesBackingArray(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// s := make([]int, 5); u := s[1:3]; u[0] = 7
+	s := mem.MakeSlice(intTy, symbolic.NewIntConstant(5), nil)
+	u := mem.Reslice(s, symbolic.NewIntConstant(1), symbolic.NewIntConstant(3), nil)
+	mem.AssignToSlice(u, symbolic.NewIntConstant(0), symbolic.NewIntConstant(7))
+
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(mem.GetFromSlice(s, symbolic.NewIntConstant(1)), symbolic.NewIntConstant(7), symbolic.NE)) {
+		t.Error("Expected s[1] == 7 after writing u[0]")
+	}
+	// Остальные элементы остаются нулевыми
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(mem.GetFromSlice(s, symbolic.NewIntConstant(2)), symbolic.NewIntConstant(0), symbolic.NE)) {
+		t.Error("Expected s[2] == 0 after make")
+	}
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(u.Cap, symbolic.NewIntConstant(4), symbolic.NE)) {
+		t.Error("Expected cap(s[1:3]) == 4")
+	}
+	for _, p := range mem.Panics() {
+		if satisfiable(t, mem, p.Cond) {
+			t.Errorf("Unexpected feasible panic: %v", p)
+		}
+	}
+}
+
+func TestInputSliceBounds(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// func f(s []int, i int) int { return s[i] }
+	s := mem.InputSlice("s", intTy)
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	mem.GetFromSlice(s, i)
+
+	panics := mem.Panics()
+	if len(panics) != 1 || panics[0].Kind != memory.IndexOutOfRange {
+		t.Fatalf("Expected one index panic, got %v", panics)
+	}
+	if !satisfiable(t, mem, panics[0].Cond) {
+		t.Error("Expected out of range index to be feasible")
+	}
+	if satisfiable(t, mem, panics[0].Cond, symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(0), symbolic.GE), symbolic.NewBinaryOperation(i, s.Len, symbolic.LT)) {
+		t.Error("Expected no panic for 0 <= i < len(s)")
+	}
+}
+
+func TestAppendAliasingDependsOnCapacity(t *testing.T) {
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	for _, tc := range []struct {
+		capacity int64
+		aliased  bool
+	}{
+		{capacity: 2, aliased: true},
+		{capacity: 1, aliased: false},
+	} {
+		mem := memory.NewSymbolicMemory()
+		// s := make([]int, 1, capacity); a := append(s, 1); b := append(s, 2)
+		s := mem.MakeSlice(intTy, symbolic.NewIntConstant(1), symbolic.NewIntConstant(tc.capacity))
+		a := mem.Append(s, symbolic.NewIntConstant(1))
+		mem.Append(s, symbolic.NewIntConstant(2))
+
+		// При достаточной ёмкости второй append перезаписывает a[1]
+		a1 := mem.GetFromSlice(a, symbolic.NewIntConstant(1))
+		overwritten := satisfiable(t, mem, symbolic.NewBinaryOperation(a1, symbolic.NewIntConstant(2), symbolic.EQ))
+		if overwritten != tc.aliased {
+			t.Errorf("cap %d: expected a[1] == 2 to be %v", tc.capacity, tc.aliased)
+		}
+	}
+}
+
+func TestAppendToInputSlice(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// func f(s []int) { t := append(s, 999); t[0] = 5; return s[0] }
+	s := mem.InputSlice("s", intTy)
+	u := mem.Append(s, symbolic.NewIntConstant(999))
+	mem.AssignToSlice(u, symbolic.NewIntConstant(0), symbolic.NewIntConstant(5))
+	s0 := mem.GetFromSlice(s, symbolic.NewIntConstant(0))
+
+	nonEmpty := symbolic.NewBinaryOperation(s.Len, symbolic.NewIntConstant(0), symbolic.GT)
+	changed := symbolic.NewBinaryOperation(s0, symbolic.NewIntConstant(5), symbolic.EQ)
+	notChanged := symbolic.NewBinaryOperation(s0, symbolic.NewIntConstant(5), symbolic.NE)
+	fits := memory.AppendFits(s, 1)
+	if satisfiable(t, mem, nonEmpty, fits, notChanged) {
+		t.Error("Expected write through the shared array when capacity suffices")
+	}
+	if !satisfiable(t, mem, nonEmpty, symbolic.NewUnaryOperation(symbolic.UN_NOT, fits), notChanged) {
+		t.Error("Expected s to be unaffected after reallocation")
+	}
+	if !satisfiable(t, mem, nonEmpty, changed) {
+		t.Error("Expected s[0] == 5 to be feasible")
+	}
+	if satisfiable(t, mem, nonEmpty, symbolic.NewBinaryOperation(mem.GetFromSlice(u, s.Len), symbolic.NewIntConstant(999), symbolic.NE)) {
+		t.Error("Expected appended element at index len(s)")
+	}
+}
+
+func TestAppendReallocationCopiesOnlyElements(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	eq := func(x symbolic.SymbolicExpression, v int64) symbolic.SymbolicExpression {
+		return symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(v), symbolic.EQ)
+	}
+
+	// s := []int{1, 2, 3, 4}; u := append(s[1:3], 9, 9); w := u[:cap(u)]
+	s := mem.MakeSlice(intTy, symbolic.NewIntConstant(4), nil)
+	for i := int64(0); i < 4; i++ {
+		mem.AssignToSlice(s, symbolic.NewIntConstant(i), symbolic.NewIntConstant(i+1))
+	}
+	u := mem.Append(mem.Reslice(s, symbolic.NewIntConstant(1), symbolic.NewIntConstant(3), nil), symbolic.NewIntConstant(9), symbolic.NewIntConstant(9))
+	w := mem.Reslice(u, nil, u.Cap, nil)
+
+	// Новый массив: [2 3 9 9 0 0], прежний s[3] = 4 за длиной не копируется
+	for i, want := range []int64{2, 3, 9, 9, 0, 0} {
+		wi := mem.GetFromSlice(w, symbolic.NewIntConstant(int64(i)))
+		if satisfiable(t, mem, symbolic.NewUnaryOperation(symbolic.UN_NOT, eq(wi, want))) {
+			t.Errorf("Expected w[%d] == %d after reallocation", i, want)
+		}
+	}
+	if !satisfiable(t, mem, eq(w.Len, 6)) || satisfiable(t, mem, symbolic.NewUnaryOperation(symbolic.UN_NOT, eq(w.Len, 6))) {
+		t.Error("Expected cap(u) == 6")
+	}
+
+	// func f(in []int) { t := append(in, 7); t = t[:cap(t)]; _ = t[len(in)+1] }
+	in := mem.InputSlice("in", intTy)
+	tt := mem.Append(in, symbolic.NewIntConstant(7))
+	tt = mem.Reslice(tt, nil, tt.Cap, nil)
+	past := mem.GetFromSlice(tt, symbolic.NewBinaryOperation(in.Len, symbolic.NewIntConstant(1), symbolic.ADD))
+	full := []symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(in.Len, in.Cap, symbolic.EQ),
+		symbolic.NewBinaryOperation(in.Len, symbolic.NewIntConstant(1), symbolic.GE),
+	}
+	if satisfiable(t, mem, append(full, symbolic.NewUnaryOperation(symbolic.UN_NOT, eq(past, 0)))...) {
+		t.Error("Expected cells past len to be zero in the reallocated array")
+	}
+	if !satisfiable(t, mem, append(full, eq(mem.GetFromSlice(tt, symbolic.NewIntConstant(0)), 42))...) {
+		t.Error("Expected copied elements to keep the values of the input slice")
+	}
+}
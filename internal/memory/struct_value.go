@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"go/types"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// StructValue - значение структуры (не ссылка): копируется при присваивании
+// и передаче параметром. Поля-структуры хранятся вложенными значениями
+type StructValue struct {
+	Layout *StructLayout
+	Fields []symbolic.SymbolicExpression // nil для полей-структур и неподдерживаемых типов
+	Nested []*StructValue                // значения полей-структур
+}
+
+// ZeroStruct возвращает нулевое значение структуры с раскладкой layout
+func ZeroStruct(layout *StructLayout) *StructValue {
+	return buildStruct(layout, func(f *FieldLayout, _ string) symbolic.SymbolicExpression {
+		return ZeroValue(f.Type)
+	}, "")
+}
+
+// InputStruct возвращает значение структуры-параметра name: каждое поле -
+// отдельная символьная переменная "<name>.<поле>[.<поле>...]"
+func InputStruct(name string, layout *StructLayout) *StructValue {
+	return buildStruct(layout, func(f *FieldLayout, path string) symbolic.SymbolicExpression {
+		if f.Type.ExprTy == symbolic.ArrayType {
+			return symbolic.NewSymbolicVariableArray(path, *f.Type.InnerTy)
+		}
+		return symbolic.NewSymbolicVariable(path, f.Type.ExprTy)
+	}, name)
+}
+
+func buildStruct(layout *StructLayout, leaf func(f *FieldLayout, path string) symbolic.SymbolicExpression, prefix string) *StructValue {
+	sv := &StructValue{
+		Layout: layout,
+		Fields: make([]symbolic.SymbolicExpression, len(layout.Fields)),
+		Nested: make([]*StructValue, len(layout.Fields)),
+	}
+	for i := range layout.Fields {
+		f := &layout.Fields[i]
+		path := prefix + "." + f.Name
+		switch {
+		case f.Struct != nil:
+			sv.Nested[i] = buildStruct(f.Struct, leaf, path)
+		case f.Supported:
+			sv.Fields[i] = leaf(f, path)
+		}
+	}
+	return sv
+}
+
+// Copy возвращает копию значения: изменения копии не видны в исходном значении
+func (sv *StructValue) Copy() *StructValue {
+	res := &StructValue{
+		Layout: sv.Layout,
+		Fields: append([]symbolic.SymbolicExpression{}, sv.Fields...),
+		Nested: make([]*StructValue, len(sv.Nested)),
+	}
+	for i, nested := range sv.Nested {
+		if nested != nil {
+			res.Nested[i] = nested.Copy()
+		}
+	}
+	return res
+}
+
+// Get возвращает значение поля по пути индексов, например emp.Person.Age - Get(0, 1)
+func (sv *StructValue) Get(path ...int) symbolic.SymbolicExpression {
+	owner := sv.GetStruct(path[:len(path)-1]...)
+	value := owner.Fields[path[len(path)-1]]
+	if value == nil {
+		panic("field " + owner.Layout.Name + "." + owner.Layout.Fields[path[len(path)-1]].Name + " has no symbolic value")
+	}
+	return value
+}
+
+// GetStruct возвращает вложенную структуру по пути индексов (пустой путь - саму структуру)
+func (sv *StructValue) GetStruct(path ...int) *StructValue {
+	res := sv
+	for _, idx := range path {
+		res = res.Nested[idx]
+		if res == nil {
+			panic("field path does not lead to a struct")
+		}
+	}
+	return res
+}
+
+// Set записывает значение поля по пути индексов
+func (sv *StructValue) Set(value symbolic.SymbolicExpression, path ...int) {
+	sv.GetStruct(path[:len(path)-1]...).Fields[path[len(path)-1]] = value
+}
+
+// SetStruct записывает копию value во вложенную структуру по пути индексов
+func (sv *StructValue) SetStruct(value *StructValue, path ...int) {
+	sv.GetStruct(path[:len(path)-1]...).Nested[path[len(path)-1]] = value.Copy()
+}
+
+// Equal строит условие равенства структур (оператор == в Go).
+// Поля неподдерживаемых типов не сравниваются
+func (sv *StructValue) Equal(other *StructValue) symbolic.SymbolicExpression {
+	var operands []symbolic.SymbolicExpression
+	other.leaves(nil, func(path []int, _ *FieldLayout, value symbolic.SymbolicExpression) {
+		if mine := sv.GetStruct(path[:len(path)-1]...).Fields[path[len(path)-1]]; mine != nil {
+			operands = append(operands, symbolic.NewBinaryOperation(mine, value, symbolic.EQ))
+		}
+	})
+	if len(operands) == 0 {
+		return symbolic.NewBoolConstant(true)
+	}
+	return symbolic.NewLogicalOperation(operands, symbolic.AND)
+}
+
+// String возвращает строковое представление значения
+func (sv *StructValue) String() string {
+	var fields []string
+	for i, f := range sv.Layout.Fields {
+		switch {
+		case sv.Nested[i] != nil:
+			fields = append(fields, f.Name+": "+sv.Nested[i].String())
+		case sv.Fields[i] != nil:
+			fields = append(fields, f.Name+": "+sv.Fields[i].String())
+		}
+	}
+	return sv.Layout.Name + "{" + strings.Join(fields, ", ") + "}"
+}
+
+// leaves обходит поля с символьными значениями, передавая полный путь до поля
+func (sv *StructValue) leaves(prefix []int, fn func(path []int, f *FieldLayout, value symbolic.SymbolicExpression)) {
+	for i := range sv.Layout.Fields {
+		path := append(append([]int{}, prefix...), i)
+		switch {
+		case sv.Nested[i] != nil:
+			sv.Nested[i].leaves(path, fn)
+		case sv.Fields[i] != nil:
+			fn(path, &sv.Layout.Fields[i], sv.Fields[i])
+		}
+	}
+}
+
+// layoutLeaves обходит поля раскладки, представимые символьными выражениями
+func layoutLeaves(layout *StructLayout, prefix []int, fn func(path []int, f *FieldLayout)) {
+	for i := range layout.Fields {
+		f := &layout.Fields[i]
+		path := append(append([]int{}, prefix...), i)
+		switch {
+		case f.Struct != nil:
+			layoutLeaves(f.Struct, path, fn)
+		case f.Supported:
+			fn(path, f)
+		}
+	}
+}
+
+// LoadStruct читает значение структуры, на которую указывает ref
+func (mem *SymbolicMemory) LoadStruct(ref *symbolic.Ref) *StructValue {
+	layout := mem.structLayout(ref.StructName)
+	mem.checkNil(ref)
+	sv := ZeroStruct(layout)
+	layoutLeaves(layout, nil, func(path []int, f *FieldLayout) {
+		sv.Set(mem.getPath(ref, path, f.Type), path...)
+	})
+	return sv
+}
+
+// StoreStruct записывает значение структуры в объект ref (*ref = sv)
+func (mem *SymbolicMemory) StoreStruct(ref *symbolic.Ref, sv *StructValue) {
+	mem.checkNil(ref)
+	sv.leaves(nil, func(path []int, _ *FieldLayout, value symbolic.SymbolicExpression) {
+		mem.assignPath(ref, path, value)
+	})
+}
+
+// GetFieldPath читает поле объекта по пути индексов, например emp.Person.Age
+func (mem *SymbolicMemory) GetFieldPath(ref *symbolic.Ref, path ...int) symbolic.SymbolicExpression {
+	mem.checkNil(ref)
+	return mem.getPath(ref, path, mem.fieldAt(mem.structLayout(ref.StructName), path).Type)
+}
+
+// AssignFieldPath записывает поле объекта по пути индексов
+func (mem *SymbolicMemory) AssignFieldPath(ref *symbolic.Ref, value symbolic.SymbolicExpression, path ...int) symbolic.SymbolicExpression {
+	mem.checkNil(ref)
+	return mem.assignPath(ref, path, value)
+}
+
+// AllocateStructArray выделяет массив длины length структур типа elem, заполненный
+// нулевыми значениями. Элементы хранятся по полям: на каждое поле - отдельный массив
+func (mem *SymbolicMemory) AllocateStructArray(elem types.Type, length symbolic.SymbolicExpression) *symbolic.Ref {
+	layout := mem.layout(elem)
+	ref := mem.allocate(symbolic.Array, "[]"+layout.Name)
+	layoutLeaves(layout, nil, func(path []int, f *FieldLayout) {
+		if zero := ZeroValue(f.Type); zero != nil {
+			mem.assignPath(ref, path, symbolic.NewArrayConstant(zero))
+		}
+	})
+	mem.assign(ref, arrayLen, length)
+	return ref
+}
+
+// GetStructFromArray читает (копию) элемента массива структур
+func (mem *SymbolicMemory) GetStructFromArray(ref *symbolic.Ref, index symbolic.SymbolicExpression) *StructValue {
+	mem.checkIndex(ref, index)
+	layout := mem.structLayout(strings.TrimPrefix(ref.StructName, "[]"))
+	sv := ZeroStruct(layout)
+	layoutLeaves(layout, nil, func(path []int, f *FieldLayout) {
+		elemTy := f.Type
+		column := mem.getPath(ref, path, symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy})
+		sv.Set(symbolic.NewBinaryOperation(column, index, symbolic.SELECT), path...)
+	})
+	return sv
+}
+
+// AssignStructToArray записывает значение структуры в элемент массива
+func (mem *SymbolicMemory) AssignStructToArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, sv *StructValue) {
+	mem.checkIndex(ref, index)
+	sv.leaves(nil, func(path []int, f *FieldLayout, value symbolic.SymbolicExpression) {
+		mem.assignElem(ref, path, index, value)
+	})
+}
+
+func (mem *SymbolicMemory) structLayout(name string) *StructLayout {
+	if mem.types != nil {
+		if layout, ok := mem.types.Lookup(name); ok {
+			return layout
+		}
+	}
+	panic("unknown struct " + name)
+}
+
+// fieldAt возвращает раскладку поля по пути индексов
+func (mem *SymbolicMemory) fieldAt(layout *StructLayout, path []int) *FieldLayout {
+	for _, idx := range path[:len(path)-1] {
+		layout = layout.Fields[idx].Struct
+		if layout == nil {
+			panic("field path does not lead to a struct")
+		}
+	}
+	field := &layout.Fields[path[len(path)-1]]
+	if !field.Supported || field.Struct != nil {
+		panic("field " + layout.Name + "." + field.Name + " has no symbolic value")
+	}
+	return field
+}
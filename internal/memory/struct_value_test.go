@@ -0,0 +1,97 @@
+package memory_test
+
+import (
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+const structsSrc = `package examples
+type Person struct {
+	Name string
+	Age  int
+	ID   int
+}
+type Address struct {
+	Street  string
+	City    string
+	ZipCode int
+}
+type Employee struct {
+	Person  Person
+	Address Address
+	Salary  float64
+}`
+
+func TestStructValueSemanticsAndNestedFields(t *testing.T) {
+	pkg := checkPackage(t, "examples", structsSrc)
+	registry := memory.NewTypeRegistry()
+	registry.RegisterPackage(pkg)
+	mem := memory.NewSymbolicMemory()
+	mem.SetTypeRegistry(registry)
+	person := registry.Layout(pkg.Scope().Lookup("Person").Type())
+	one := symbolic.NewIntConstant(1)
+
+	// func testStructModification(p Person) Person { p.Age = p.Age + 1; return p }
+	p := memory.InputStruct("p", person)
+	q := p.Copy()
+	q.Set(symbolic.NewBinaryOperation(q.Get(1), one, symbolic.ADD), 1)
+	if p.Get(1).String() != "p.Age" {
+		t.Errorf("Expected caller's copy to be unchanged, got %v", p)
+	}
+	if satisfiable(t, mem, q.Equal(p)) {
+		t.Error("Expected modified copy to differ from the original")
+	}
+
+	// func testNestedStructModification(emp *Employee) { emp.Person.Age += 1; emp.Address.ZipCode = 54321 }
+	emp := mem.InputRef("emp", symbolic.Object, "examples.Employee")
+	before := mem.LoadStruct(emp)
+	age := mem.GetFieldPath(emp, 0, 1)
+	mem.AssignFieldPath(emp, symbolic.NewBinaryOperation(age, one, symbolic.ADD), 0, 1)
+	mem.AssignFieldPath(emp, symbolic.NewIntConstant(54321), 1, 2)
+	after := mem.LoadStruct(emp)
+
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(after.Get(0, 1), symbolic.NewBinaryOperation(before.Get(0, 1), one, symbolic.ADD), symbolic.NE)) {
+		t.Error("Expected emp.Person.Age to be incremented")
+	}
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(after.Get(0, 2), before.Get(0, 2), symbolic.NE)) {
+		t.Error("Expected emp.Person.ID to be unchanged")
+	}
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(after.Get(1, 2), symbolic.NewIntConstant(54321), symbolic.NE)) {
+		t.Error("Expected emp.Address.ZipCode == 54321")
+	}
+
+	// *emp = Employee{Person: p}: копия значения, а не ссылка на него
+	stored := memory.ZeroStruct(registry.Layout(pkg.Scope().Lookup("Employee").Type()))
+	stored.SetStruct(p, 0)
+	mem.StoreStruct(emp, stored)
+	p.Set(symbolic.NewIntConstant(7), 1)
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(mem.GetFieldPath(emp, 0, 1), symbolic.NewSymbolicVariable("p.Age", symbolic.IntType), symbolic.NE)) {
+		t.Error("Expected stored struct to be a copy")
+	}
+}
+
+func TestArrayOfStructs(t *testing.T) {
+	pkg := checkPackage(t, "examples", structsSrc)
+	mem := memory.NewSymbolicMemory()
+	personTy := pkg.Scope().Lookup("Person").Type()
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	j := symbolic.NewSymbolicVariable("j", symbolic.IntType)
+
+	// var people [3]Person; people[i] = Person{Age: 25, ID: 1}; return people[j]
+	people := mem.AllocateStructArray(personTy, symbolic.NewIntConstant(3))
+	alice := memory.ZeroStruct(mem.TypeRegistry().Layout(personTy))
+	alice.Set(symbolic.NewIntConstant(25), 1)
+	alice.Set(symbolic.NewIntConstant(1), 2)
+	mem.AssignStructToArray(people, i, alice)
+	got := mem.GetStructFromArray(people, j)
+
+	same := symbolic.NewBinaryOperation(i, j, symbolic.EQ)
+	if satisfiable(t, mem, same, symbolic.NewUnaryOperation(symbolic.UN_NOT, got.Equal(alice))) {
+		t.Error("Expected people[j] == alice when i == j")
+	}
+	if satisfiable(t, mem, symbolic.NewUnaryOperation(symbolic.UN_NOT, same), symbolic.NewBinaryOperation(got.Get(1), symbolic.NewIntConstant(0), symbolic.NE)) {
+		t.Error("Expected zero Age in other elements")
+	}
+}
@@ -1,81 +1,401 @@
 package memory
 
 import (
+	"go/types"
+	"sort"
+	"strconv"
+
 	"symbolic-execution-course/internal/symbolic"
 )
 
 type Memory interface {
-	Allocate(tpe symbolic.ExpressionType) *symbolic.Ref
+	AllocatePrimitive(tpe symbolic.ExpressionType) *symbolic.Ref
+	AllocateStruct(structName string) *symbolic.Ref
+	AllocateArray(elemTy symbolic.InnerType, length symbolic.SymbolicExpression) *symbolic.Ref
+	AllocateSlice(elemTy symbolic.InnerType, capacity symbolic.SymbolicExpression) *symbolic.Ref
+
+	// InputRef создаёт ссылку с символьным адресом для указателя-параметра
+	InputRef(name string, memTy symbolic.MemType, typeName string) *symbolic.Ref
+	InputArray(name string, elemTy symbolic.InnerType, length symbolic.SymbolicExpression) *symbolic.Ref
 
 	AssignPrimitive(ref *symbolic.Ref, value symbolic.SymbolicExpression)
 	GetPrimitive(ref *symbolic.Ref) symbolic.SymbolicExpression
+	// PeekPrimitive читает примитив без изменения памяти (для трансляции ссылок)
+	PeekPrimitive(ref *symbolic.Ref) symbolic.SymbolicExpression
 
 	AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression
 	GetFieldValue(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression
+	// PeekField читает поле без проверок и без изменения памяти (для чтения моделей)
+	PeekField(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression
+
+	AssignToArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, value symbolic.SymbolicExpression) symbolic.SymbolicExpression
+	GetFromArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, elemTy symbolic.InnerType) symbolic.SymbolicExpression
+	ArrayLen(ref *symbolic.Ref) symbolic.SymbolicExpression
+
+	MakeSlice(elemTy symbolic.InnerType, length, capacity symbolic.SymbolicExpression) *Slice
+	InputSlice(name string, elemTy symbolic.InnerType) *Slice
+	Reslice(s *Slice, low, high, max symbolic.SymbolicExpression) *Slice
+	GetFromSlice(s *Slice, index symbolic.SymbolicExpression) symbolic.SymbolicExpression
+	AssignToSlice(s *Slice, index, value symbolic.SymbolicExpression) symbolic.SymbolicExpression
+	Append(s *Slice, values ...symbolic.SymbolicExpression) *Slice
+
+	MakeMap(keyTy, valueTy symbolic.InnerType) *symbolic.Ref
+	NilMap(keyTy, valueTy symbolic.InnerType) *symbolic.Ref
+	InputMap(name string, keyTy, valueTy symbolic.InnerType) *symbolic.Ref
+	MapLookup(m *symbolic.Ref, key symbolic.SymbolicExpression, valueTy symbolic.InnerType) (symbolic.SymbolicExpression, symbolic.SymbolicExpression)
+	MapAssign(m *symbolic.Ref, key, value symbolic.SymbolicExpression)
+	MapDelete(m *symbolic.Ref, key symbolic.SymbolicExpression)
+	MapLen(m *symbolic.Ref) symbolic.SymbolicExpression
+
+	AllocateType(t types.Type) *symbolic.Ref
+	GetField(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression
+	GetFieldPath(ref *symbolic.Ref, path ...int) symbolic.SymbolicExpression
+	AssignFieldPath(ref *symbolic.Ref, value symbolic.SymbolicExpression, path ...int) symbolic.SymbolicExpression
+	LoadStruct(ref *symbolic.Ref) *StructValue
+	StoreStruct(ref *symbolic.Ref, sv *StructValue)
+	AllocateStructArray(elem types.Type, length symbolic.SymbolicExpression) *symbolic.Ref
+	GetStructFromArray(ref *symbolic.Ref, index symbolic.SymbolicExpression) *StructValue
+	AssignStructToArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, sv *StructValue)
 
-	// We can reuse AssignField and GetFieldValue for arrays
-	AssignToArray(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression)
-	GetFromArray(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression
+	InputPointer(name string, typeName string) *symbolic.Ref
+	SetHeapInvariants(inv HeapInvariants)
+	LoadPointer(ref *symbolic.Ref, fieldIdx int, typeName string) *symbolic.Ref
+
+	// Constraints возвращает аксиомы кучи, которые нужно добавить в solver
+	Constraints() []symbolic.SymbolicExpression
+	// Panics возвращает условия возможных паник выполненных операций
+	Panics() []RuntimePanic
 }
 
+// SymbolicMemory должна реализовывать Memory
+var _ Memory = (*SymbolicMemory)(nil)
+
+// SymbolicMemory моделирует кучу теорией массивов: на каждое поле каждого типа
+// заводится SMT массив "адрес -> значение поля", чтение поля - select,
+// запись - store поверх текущего массива.
+//
+// Адрес 0 зарезервирован под nil, объекты, выделенные программой, получают
+// положительные адреса, а указатели-параметры - символьные адреса с ограничением
+// addr < 0: они могут совпадать друг с другом, но не с новыми объектами.
+//
+// Содержимое массивов хранится так же, как единственное поле: массив кучи
+// отображает адрес массива в SMT массив его элементов
 type SymbolicMemory struct {
-	// Note: надо использовать SMT массивы для хранения адресов
-	// Note: для организации полей у объектов также используем массивы:
-	//       заводим по массиву на каждое поле класса, индексация в
-	//       массиве будет производиться по expression этого поля
-	// Note: чтобы получать соответствующий массив для поля, делаем map
-	PrimitivesMap map[symbolic.Ref]symbolic.SymbolicExpression
-	ObjectsMap    map[int]map[int]symbolic.SymbolicExpression
-	ObjectsCnt    int
-	ArraysMap     map[int]map[int]symbolic.SymbolicExpression
-	ArraysCnt     int
-}
-
-func NewSymbolicMemory() SymbolicMemory {
-	return SymbolicMemory{
-		PrimitivesMap: make(map[symbolic.Ref]symbolic.SymbolicExpression),
-		ObjectsMap:    make(map[int]map[int]symbolic.SymbolicExpression),
+	Heap    map[string]symbolic.SymbolicExpression // "<тип>.<индекс поля>" -> массив поля
+	AddrCnt int
+	Inputs  []*symbolic.Ref
+
+	axioms  []symbolic.SymbolicExpression
+	panics  []RuntimePanic
+	path    []symbolic.SymbolicExpression // условия ветвлений текущего пути, см. AssumePath
+	objects []*symbolic.Ref               // объекты AllocateStruct, поля которых начинаются с нулей
+	copies  []arrayCopy                   // массивы, выделенные в Append
+
+	// Ленивая инициализация входных указателей
+	lazyInitDepth   int
+	lazyDepth       map[exprID]int                           // адрес -> число разыменований от параметра
+	lazyObjects     map[string][]symbolic.SymbolicExpression // тип -> адреса входных объектов
+	lazyInitialized map[fieldID]bool                         // уже инициализированные поля
+	lazySlots       map[string][]lazySlot                    // тип -> инициализированные поля-указатели на него
+	lazyCnt         int                                      // число созданных входных объектов, для их имён
+	invariants      HeapInvariants
+
+	types *TypeRegistry // раскладки структур, см. SetTypeRegistry
+
+	// Таблицы разделяются с другой копией после Fork
+	heapShared bool
+	lazyShared bool
+}
+
+func NewSymbolicMemory() *SymbolicMemory {
+	return &SymbolicMemory{
+		Heap:            make(map[string]symbolic.SymbolicExpression),
+		lazyInitDepth:   DefaultLazyInitDepth,
+		lazyDepth:       make(map[exprID]int),
+		lazyObjects:     make(map[string][]symbolic.SymbolicExpression),
+		lazyInitialized: make(map[fieldID]bool),
+		lazySlots:       make(map[string][]lazySlot),
+	}
+}
+
+// HeapKey возвращает ключ массива поля объектов типа typeName. Поле задаётся
+// путём индексов: для полей вложенных структур путь длиннее одного
+func HeapKey(typeName string, path ...int) string {
+	key := typeName
+	for _, idx := range path {
+		key += "." + strconv.Itoa(idx)
 	}
+	return key
+}
+
+func (mem *SymbolicMemory) allocate(memTy symbolic.MemType, typeName string) *symbolic.Ref {
+	mem.AddrCnt += 1
+	return &symbolic.Ref{Addr: symbolic.NewIntConstant(int64(mem.AddrCnt)), MemTy: memTy, StructName: typeName}
 }
 
-func (mem *SymbolicMemory) Allocate(tpe symbolic.ExpressionType, structName string) *symbolic.Ref {
+// AllocatePrimitive выделяет ячейку под значение примитивного типа: int, bool
+// или указателя. Объекты и массивы выделяются AllocateStruct и AllocateArray
+func (mem *SymbolicMemory) AllocatePrimitive(tpe symbolic.ExpressionType) *symbolic.Ref {
 	switch tpe {
-	case symbolic.ObjectType:
-		mem.ObjectsCnt += 1
-		return &symbolic.Ref{MemTy: symbolic.Object, ObjectAddr: mem.ObjectsCnt, ArrayAddr: -1, StructName: structName}
+	case symbolic.IntType, symbolic.BoolType, symbolic.RefType:
+		return mem.allocate(symbolic.Primitive, tpe.String())
 	default:
-		// Primitives
-		return &symbolic.Ref{MemTy: symbolic.Primitive, ArrayAddr: -1, ObjectAddr: -1}
+		panic("cannot allocate primitive of type " + tpe.String() + ", use AllocateStruct or AllocateArray")
+	}
+}
+
+// AllocateStruct выделяет объект структуры structName, заполненный нулевыми значениями.
+// Если раскладка структуры неизвестна, обнуляются уже заведённые массивы её полей,
+// а массивы, заведённые позже, начинаются с нулей для всех таких объектов (см. heapOrInit)
+func (mem *SymbolicMemory) AllocateStruct(structName string) *symbolic.Ref {
+	ref := mem.allocate(symbolic.Object, structName)
+	var layout *StructLayout
+	if mem.types != nil {
+		layout, _ = mem.types.Lookup(structName)
+	}
+	if layout != nil {
+		layoutLeaves(layout, nil, func(path []int, f *FieldLayout) {
+			if zero := ZeroValue(f.Type); zero != nil {
+				mem.assignPath(ref, path, zero)
+			}
+		})
+	} else {
+		keys := make([]string, 0, len(mem.Heap))
+		for key := range mem.Heap {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			typeName, path := splitHeapKey(key)
+			if zero := ZeroValue(*symbolic.InnerTypeOf(mem.Heap[key]).InnerTy); typeName == structName && zero != nil {
+				mem.assignPath(ref, path, zero)
+			}
+		}
+	}
+	mem.objects = append(mem.objects, ref)
+	return ref
+}
+
+// Длина массива хранится по особому пути, который не пересекается
+// с путями полей элементов массивов структур
+const arrayLen = -1
+
+// AllocateArray выделяет массив длины length с элементами типа elemTy,
+// заполненный нулевыми значениями
+func (mem *SymbolicMemory) AllocateArray(elemTy symbolic.InnerType, length symbolic.SymbolicExpression) *symbolic.Ref {
+	arrTy := symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy}
+	ref := mem.allocate(symbolic.Array, arrTy.String())
+	if zero := ZeroValue(elemTy); zero != nil {
+		mem.assign(ref, 0, symbolic.NewArrayConstant(zero))
+	}
+	mem.assign(ref, arrayLen, length)
+	return ref
+}
+
+// AllocateSlice выделяет базовый массив слайса ёмкости capacity с элементами типа elemTy
+func (mem *SymbolicMemory) AllocateSlice(elemTy symbolic.InnerType, capacity symbolic.SymbolicExpression) *symbolic.Ref {
+	return mem.AllocateArray(elemTy, capacity)
+}
+
+// ArrayLen возвращает длину массива ref
+func (mem *SymbolicMemory) ArrayLen(ref *symbolic.Ref) symbolic.SymbolicExpression {
+	return mem.get(ref, arrayLen, symbolic.InnerType{ExprTy: symbolic.IntType})
+}
+
+// ZeroValue возвращает нулевое значение типа ty или nil, если оно не выражается константой
+func ZeroValue(ty symbolic.InnerType) symbolic.SymbolicExpression {
+	switch ty.ExprTy {
+	case symbolic.IntType:
+		return symbolic.NewIntConstant(0)
+	case symbolic.BoolType:
+		return symbolic.NewBoolConstant(false)
+	case symbolic.RefType:
+		return symbolic.NewNilRef("")
+	case symbolic.ArrayType:
+		// Массив фиксированной длины - значение, все его элементы нулевые
+		if ty.InnerTy == nil {
+			return nil
+		}
+		if zero := ZeroValue(*ty.InnerTy); zero != nil {
+			return symbolic.NewArrayConstant(zero)
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// InputRef создаёт ссылку на входной объект: адрес - символьная переменная name
+func (mem *SymbolicMemory) InputRef(name string, memTy symbolic.MemType, typeName string) *symbolic.Ref {
+	ref := &symbolic.Ref{Addr: symbolic.NewSymbolicVariable(name, symbolic.IntType), MemTy: memTy, StructName: typeName}
+	mem.Inputs = append(mem.Inputs, ref)
+	if memTy == symbolic.Array {
+		mem.axioms = append(mem.axioms, symbolic.NewBinaryOperation(mem.ArrayLen(ref), symbolic.NewIntConstant(0), symbolic.GE))
+	}
+	return ref
+}
+
+// InputArray создаёт массив-параметр name длины length (nil - произвольной)
+func (mem *SymbolicMemory) InputArray(name string, elemTy symbolic.InnerType, length symbolic.SymbolicExpression) *symbolic.Ref {
+	arrTy := symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy}
+	ref := mem.InputRef(name, symbolic.Array, arrTy.String())
+	if length != nil {
+		mem.axioms = append(mem.axioms, symbolic.NewBinaryOperation(mem.ArrayLen(ref), length, symbolic.EQ))
 	}
+	return ref
+}
+
+// Constraints возвращает ограничения на адреса входных ссылок и длины входных слайсов.
+// Входная мапа, как и указатель InputPointer, может быть nil
+func (mem *SymbolicMemory) Constraints() []symbolic.SymbolicExpression {
+	res := append([]symbolic.SymbolicExpression{}, mem.axioms...)
+	for _, ref := range mem.Inputs {
+		op := symbolic.LT
+		if ref.MemTy == symbolic.Map {
+			op = symbolic.LE
+		}
+		res = append(res, symbolic.NewBinaryOperation(ref.Addr, symbolic.NewIntConstant(symbolic.NilAddr), op))
+	}
+	return res
 }
 
 func (mem *SymbolicMemory) AssignPrimitive(ref *symbolic.Ref, value symbolic.SymbolicExpression) {
-	mem.PrimitivesMap[*ref] = value
+	mem.assign(ref, 0, value)
 }
 
 func (mem *SymbolicMemory) GetPrimitive(ref *symbolic.Ref) symbolic.SymbolicExpression {
-	return mem.PrimitivesMap[*ref]
+	return mem.get(ref, 0, primitiveType(ref.StructName))
 }
 
-func (mem *SymbolicMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
-	if mem.ObjectsMap[ref.ObjectAddr] == nil {
-		mem.ObjectsMap[ref.ObjectAddr] = make(map[int]symbolic.SymbolicExpression)
+// PeekPrimitive возвращает текущее значение примитива, не заводя массив кучи
+func (mem *SymbolicMemory) PeekPrimitive(ref *symbolic.Ref) symbolic.SymbolicExpression {
+	return mem.PeekField(ref, 0, primitiveType(ref.StructName))
+}
+
+// primitiveType восстанавливает тип примитива по имени, записанному в ссылке
+func primitiveType(name string) symbolic.InnerType {
+	for _, ty := range []symbolic.ExpressionType{symbolic.IntType, symbolic.BoolType, symbolic.RefType} {
+		if ty.String() == name {
+			return symbolic.InnerType{ExprTy: ty}
+		}
 	}
-	res := symbolic.NewFieldAssign(ref.Expr, fieldIdx, value, ref.StructName)
-	mem.ObjectsMap[ref.ObjectAddr][fieldIdx] = ref.Expr // Remembering expression with assign
-	ref.Expr = res
-	return res
+	panic("unknown primitive type " + name)
+}
+
+func (mem *SymbolicMemory) AssignField(ref *symbolic.Ref, fieldIdx int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	mem.checkNil(ref)
+	return mem.assign(ref, fieldIdx, value)
 }
 
 func (mem *SymbolicMemory) GetFieldValue(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression {
-	key := mem.ObjectsMap[ref.ObjectAddr][fieldIdx] // Get expression for assign (its String() will be used as key)
-	return symbolic.NewFieldAccess(ref.Expr, fieldIdx, key, ref.StructName, fieldTy)
+	mem.checkNil(ref)
+	return mem.get(ref, fieldIdx, fieldTy)
+}
+
+// PeekField возвращает текущее значение поля, не регистрируя паник и не заводя
+// массив поля в куче: чтение модели уже исполненного пути не должно менять состояние
+func (mem *SymbolicMemory) PeekField(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression {
+	key := HeapKey(ref.StructName, fieldIdx)
+	heap, ok := mem.Heap[key]
+	if !ok {
+		heap = mem.initialHeap(key, fieldTy)
+	}
+	return symbolic.NewFieldAccess(heap, ref.Addr, fieldIdx, ref.StructName, fieldTy)
+}
+
+// AssignToArray записывает value в элемент массива с (возможно символьным) индексом index.
+// Запись за границы массива регистрируется как паника IndexOutOfRange
+func (mem *SymbolicMemory) AssignToArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	mem.checkIndex(ref, index)
+	return mem.storeElem(ref, index, value)
+}
+
+// GetFromArray читает элемент массива с (возможно символьным) индексом index.
+// Чтение за границами массива регистрируется как паника IndexOutOfRange
+func (mem *SymbolicMemory) GetFromArray(ref *symbolic.Ref, index symbolic.SymbolicExpression, elemTy symbolic.InnerType) symbolic.SymbolicExpression {
+	mem.checkIndex(ref, index)
+	return mem.loadElem(ref, index, elemTy)
+}
+
+// checkIndex регистрирует панику при индексе вне [0, len(ref))
+func (mem *SymbolicMemory) checkIndex(ref *symbolic.Ref, index symbolic.SymbolicExpression) {
+	mem.mayPanic(IndexOutOfRange, outside(symbolic.NewIntConstant(0), index, mem.ArrayLen(ref)))
 }
 
-func (mem *SymbolicMemory) AssignToArray(ref *symbolic.Ref, index int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
-	return mem.AssignField(ref, index, value)
+// storeElem записывает элемент массива без проверки границ
+func (mem *SymbolicMemory) storeElem(ref *symbolic.Ref, index, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return mem.assignElem(ref, []int{0}, index, value)
+}
+
+// loadElem читает элемент массива без проверки границ
+func (mem *SymbolicMemory) loadElem(ref *symbolic.Ref, index symbolic.SymbolicExpression, elemTy symbolic.InnerType) symbolic.SymbolicExpression {
+	mem.defineCopies(index, elemTy)
+	return symbolic.NewBinaryOperation(mem.arrayContents(ref, elemTy), index, symbolic.SELECT)
+}
+
+// arrayContents возвращает текущее содержимое массива ref
+func (mem *SymbolicMemory) arrayContents(ref *symbolic.Ref, elemTy symbolic.InnerType) symbolic.SymbolicExpression {
+	return mem.get(ref, 0, symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy})
+}
+
+// heapOrInit возвращает массив поля, заводя при первом обращении его исходное
+// содержимое - свободную переменную "heap_<тип>.<поле>" (см. initialHeap)
+func (mem *SymbolicMemory) heapOrInit(key string, ty symbolic.InnerType) symbolic.SymbolicExpression {
+	if heap, ok := mem.Heap[key]; ok {
+		return heap
+	}
+	heap := mem.initialHeap(key, ty)
+	mem.ownHeap()
+	mem.Heap[key] = heap
+	return heap
+}
+
+// initialHeap возвращает массив поля до первой записи в него: содержимое кучи
+// до начала исполнения, в котором поля объектов AllocateStruct уже обнулены
+func (mem *SymbolicMemory) initialHeap(key string, ty symbolic.InnerType) symbolic.SymbolicExpression {
+	var heap symbolic.SymbolicExpression = symbolic.NewSymbolicVariableArray("heap_"+key, ty)
+	typeName, path := splitHeapKey(key)
+	if zero := ZeroValue(ty); zero != nil && len(path) > 0 {
+		for _, obj := range mem.objects {
+			if obj.StructName == typeName {
+				heap = symbolic.NewFieldAssign(heap, obj.Addr, path[0], zero, typeName)
+			}
+		}
+	}
+	return heap
+}
+
+func (mem *SymbolicMemory) assign(ref *symbolic.Ref, idx int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	return mem.assignPath(ref, []int{idx}, value)
+}
+
+func (mem *SymbolicMemory) get(ref *symbolic.Ref, idx int, ty symbolic.InnerType) symbolic.SymbolicExpression {
+	return mem.getPath(ref, []int{idx}, ty)
+}
+
+func (mem *SymbolicMemory) assignPath(ref *symbolic.Ref, path []int, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	key := HeapKey(ref.StructName, path...)
+	heap := mem.heapOrInit(key, symbolic.InnerTypeOf(value))
+	res := symbolic.NewFieldAssign(heap, ref.Addr, path[0], value, ref.StructName)
+	mem.ownHeap()
+	mem.Heap[key] = res
+	return res
+}
+
+// assignElem записывает элемент index массива, хранящегося в поле path объекта ref.
+// Запись ссылается на массив кучи один раз, а не через чтение прежнего
+// содержимого, поэтому размер выражения растёт линейно с числом записей
+func (mem *SymbolicMemory) assignElem(ref *symbolic.Ref, path []int, index, value symbolic.SymbolicExpression) symbolic.SymbolicExpression {
+	elemTy := symbolic.InnerTypeOf(value)
+	key := HeapKey(ref.StructName, path...)
+	heap := mem.heapOrInit(key, symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elemTy})
+	res := symbolic.NewElementAssign(heap, ref.Addr, path[0], index, value, ref.StructName)
+	mem.ownHeap()
+	mem.Heap[key] = res
+	return res
 }
 
-func (mem *SymbolicMemory) GetFromArray(ref *symbolic.Ref, fieldIdx int, fieldTy symbolic.InnerType) symbolic.SymbolicExpression {
-	return mem.GetFieldValue(ref, fieldIdx, fieldTy)
+func (mem *SymbolicMemory) getPath(ref *symbolic.Ref, path []int, ty symbolic.InnerType) symbolic.SymbolicExpression {
+	heap := mem.heapOrInit(HeapKey(ref.StructName, path...), ty)
+	return symbolic.NewFieldAccess(heap, ref.Addr, path[0], ref.StructName, ty)
 }
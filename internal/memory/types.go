@@ -0,0 +1,164 @@
+package memory
+
+import (
+	"go/types"
+
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// FieldLayout - поле структуры в символьной памяти
+type FieldLayout struct {
+	Name      string
+	Index     int
+	Type      symbolic.InnerType
+	GoType    types.Type
+	Struct    *StructLayout // раскладка вложенной (по значению) структуры
+	Supported bool          // тип поля представим символьными выражениями
+}
+
+// StructLayout - раскладка структуры: её имя в куче и поля
+type StructLayout struct {
+	Name   string // полное имя типа с путём пакета, например "example.com/pkg.Person"
+	Fields []FieldLayout
+}
+
+// Field возвращает поле по имени
+func (sl *StructLayout) Field(name string) (*FieldLayout, bool) {
+	for i := range sl.Fields {
+		if sl.Fields[i].Name == name {
+			return &sl.Fields[i], true
+		}
+	}
+	return nil, false
+}
+
+// TypeRegistry строит раскладки структур по типам go/types. Структуры
+// различаются по полному имени с путём пакета, поэтому одноимённые типы
+// из разных пакетов не смешиваются
+type TypeRegistry struct {
+	byName map[string]*StructLayout
+}
+
+// NewTypeRegistry создаёт пустой реестр типов
+func NewTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{byName: make(map[string]*StructLayout)}
+}
+
+// RegisterPackage регистрирует все именованные структуры пакета
+// (для SSA программы - каждого Pkg из prog.AllPackages())
+func (r *TypeRegistry) RegisterPackage(pkg *types.Package) {
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+			if _, isStruct := tn.Type().Underlying().(*types.Struct); isStruct {
+				r.Layout(tn.Type())
+			}
+		}
+	}
+}
+
+// Layout возвращает раскладку структуры t (именованной, анонимной или
+// указателя на структуру), строя её при первом обращении
+func (r *TypeRegistry) Layout(t types.Type) *StructLayout {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		panic("not a struct type: " + t.String())
+	}
+	name := types.TypeString(t, nil)
+	if layout, ok := r.byName[name]; ok {
+		return layout
+	}
+
+	layout := &StructLayout{Name: name}
+	r.byName[name] = layout // до обхода полей: структура может ссылаться на себя через указатель
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		field := FieldLayout{Name: f.Name(), Index: i, GoType: f.Type()}
+		field.Type, field.Supported = r.innerType(f.Type())
+		if _, isStruct := f.Type().Underlying().(*types.Struct); isStruct {
+			field.Struct = r.Layout(f.Type())
+		}
+		layout.Fields = append(layout.Fields, field)
+	}
+	return layout
+}
+
+// Lookup возвращает зарегистрированную раскладку по имени структуры
+func (r *TypeRegistry) Lookup(name string) (*StructLayout, bool) {
+	layout, ok := r.byName[name]
+	return layout, ok
+}
+
+// InnerType возвращает символьный тип для типа Go
+func (r *TypeRegistry) InnerType(t types.Type) symbolic.InnerType {
+	ty, ok := r.innerType(t)
+	if !ok {
+		panic("unsupported type " + t.String())
+	}
+	return ty
+}
+
+func (r *TypeRegistry) innerType(t types.Type) (symbolic.InnerType, bool) {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsInteger != 0:
+			return symbolic.InnerType{ExprTy: symbolic.IntType}, true
+		case u.Info()&types.IsBoolean != 0:
+			return symbolic.InnerType{ExprTy: symbolic.BoolType}, true
+		}
+	case *types.Array:
+		elem, ok := r.innerType(u.Elem())
+		return symbolic.InnerType{ExprTy: symbolic.ArrayType, InnerTy: &elem}, ok
+	case *types.Pointer, *types.Slice, *types.Map:
+		return symbolic.InnerType{ExprTy: symbolic.RefType}, true
+	case *types.Struct:
+		return symbolic.InnerType{ExprTy: symbolic.ObjectType}, true
+	}
+	return symbolic.InnerType{}, false
+}
+
+// SetTypeRegistry задаёт реестр типов, по которому определяются типы полей
+func (mem *SymbolicMemory) SetTypeRegistry(registry *TypeRegistry) {
+	mem.types = registry
+}
+
+// TypeRegistry возвращает реестр типов памяти (может быть nil)
+func (mem *SymbolicMemory) TypeRegistry() *TypeRegistry {
+	return mem.types
+}
+
+// AllocateType выделяет объект структуры t, заполненный нулевыми значениями
+func (mem *SymbolicMemory) AllocateType(t types.Type) *symbolic.Ref {
+	return mem.AllocateStruct(mem.layout(t).Name)
+}
+
+// GetField читает поле fieldIdx, определяя его тип по раскладке структуры
+func (mem *SymbolicMemory) GetField(ref *symbolic.Ref, fieldIdx int) symbolic.SymbolicExpression {
+	return mem.GetFieldValue(ref, fieldIdx, mem.fieldLayout(ref.StructName, fieldIdx).Type)
+}
+
+func (mem *SymbolicMemory) layout(t types.Type) *StructLayout {
+	if mem.types == nil {
+		mem.types = NewTypeRegistry()
+	}
+	return mem.types.Layout(t)
+}
+
+func (mem *SymbolicMemory) fieldLayout(structName string, fieldIdx int) *FieldLayout {
+	if mem.types == nil {
+		panic("type registry is not set, field types of " + structName + " are unknown")
+	}
+	layout, ok := mem.types.Lookup(structName)
+	if !ok {
+		panic("unknown struct " + structName)
+	}
+	field := &layout.Fields[fieldIdx]
+	if !field.Supported {
+		panic("unsupported type of field " + structName + "." + field.Name)
+	}
+	return field
+}
@@ -0,0 +1,140 @@
+package memory_test
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+)
+
+// checkPackage проверяет типы пакета path из одного файла src
+func checkPackage(t *testing.T, path, src string) *types.Package {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path+".go", src, 0)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %v", path, err)
+	}
+	pkg, err := (&types.Config{Importer: importer.Default()}).Check(path, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("Error type checking %s: %v", path, err)
+	}
+	return pkg
+}
+
+func TestTypeRegistryLayouts(t *testing.T) {
+	a := checkPackage(t, "example.com/a", `package a
+type Person struct {
+	Name string
+	Age  int
+	ID   int
+}
+type Employee struct {
+	Person  Person
+	Manager *Employee
+	Grades  [5]int
+}`)
+	b := checkPackage(t, "example.com/b", `package b
+type Person struct {
+	Alive bool
+}`)
+
+	registry := memory.NewTypeRegistry()
+	registry.RegisterPackage(a)
+	registry.RegisterPackage(b)
+
+	personA, ok := registry.Lookup("example.com/a.Person")
+	if !ok || len(personA.Fields) != 3 {
+		t.Fatalf("Expected layout of a.Person with 3 fields, got %v", personA)
+	}
+	if age, _ := personA.Field("Age"); age.Index != 1 || age.Type.ExprTy != symbolic.IntType {
+		t.Errorf("Expected Age to be int field 1, got %+v", age)
+	}
+	if name, _ := personA.Field("Name"); name.Supported {
+		t.Error("Expected string field to be unsupported")
+	}
+	if personB, _ := registry.Lookup("example.com/b.Person"); personB.Fields[0].Type.ExprTy != symbolic.BoolType {
+		t.Errorf("Expected b.Person to have its own layout, got %v", personB)
+	}
+
+	employee := registry.Layout(a.Scope().Lookup("Employee").Type())
+	if employee.Fields[0].Struct != personA {
+		t.Error("Expected nested Person layout")
+	}
+	if employee.Fields[1].Type.ExprTy != symbolic.RefType || employee.Fields[2].Type.String() != "[]int" {
+		t.Errorf("Unexpected field types: %v, %v", employee.Fields[1].Type, employee.Fields[2].Type)
+	}
+
+	// Типы полей определяются памятью автоматически
+	mem := memory.NewSymbolicMemory()
+	mem.SetTypeRegistry(registry)
+	p := mem.AllocateType(types.NewPointer(a.Scope().Lookup("Person").Type()))
+	if p.StructName != "example.com/a.Person" {
+		t.Errorf("Expected qualified struct name, got %s", p.StructName)
+	}
+	age := mem.GetField(p, 1)
+	if age.Type() != symbolic.IntType || satisfiable(t, mem, symbolic.NewBinaryOperation(age, symbolic.NewIntConstant(0), symbolic.NE)) {
+		t.Errorf("Expected zero-initialized int field, got %v", age)
+	}
+}
+
+func TestAllocateStructZeroesFields(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+	boolTy := symbolic.InnerType{ExprTy: symbolic.BoolType}
+
+	// p, q := &Point{}, &Point{}; q.x = 5; r := &Point{}
+	p := mem.AllocateStruct("Point")
+	q := mem.AllocateStruct("Point")
+	mem.AssignField(q, 0, symbolic.NewIntConstant(5))
+	r := mem.AllocateStruct("Point")
+
+	for _, c := range []struct {
+		name  string
+		value symbolic.SymbolicExpression
+		zero  symbolic.SymbolicExpression
+	}{
+		{"p.x", mem.GetFieldValue(p, 0, intTy), symbolic.NewIntConstant(0)},
+		{"r.x", mem.GetFieldValue(r, 0, intTy), symbolic.NewIntConstant(0)},
+		{"q.y", mem.GetFieldValue(q, 1, intTy), symbolic.NewIntConstant(0)},
+		{"r.ok", mem.GetFieldValue(r, 2, boolTy), symbolic.NewBoolConstant(false)},
+	} {
+		if satisfiable(t, mem, symbolic.NewBinaryOperation(c.value, c.zero, symbolic.NE)) {
+			t.Errorf("Expected %s of a new object to be zero", c.name)
+		}
+	}
+	if satisfiable(t, mem, symbolic.NewBinaryOperation(mem.GetFieldValue(q, 0, intTy), symbolic.NewIntConstant(5), symbolic.NE)) {
+		t.Error("Expected q.x == 5")
+	}
+}
+
+func TestZeroedArrayFields(t *testing.T) {
+	pkg := checkPackage(t, "buffers", `package buffers
+type Buf struct {
+	Data [3]int
+	N    int
+}`)
+	mem := memory.NewSymbolicMemory()
+	bufTy := pkg.Scope().Lookup("Buf").Type()
+	k := symbolic.NewSymbolicVariable("k", symbolic.IntType)
+	j := symbolic.NewSymbolicVariable("j", symbolic.IntType)
+	zero := symbolic.NewIntConstant(0)
+
+	// b := &Buf{}; var bufs [2]Buf
+	b := mem.AllocateType(bufTy)
+	bufs := mem.AllocateStructArray(bufTy, symbolic.NewIntConstant(2))
+	for name, data := range map[string]symbolic.SymbolicExpression{
+		"b.Data":       mem.GetField(b, 0),
+		"bufs[j].Data": mem.GetStructFromArray(bufs, j).Get(0),
+	} {
+		elem := symbolic.NewBinaryOperation(data, k, symbolic.SELECT)
+		if satisfiable(t, mem, symbolic.NewBinaryOperation(elem, zero, symbolic.NE)) {
+			t.Errorf("Expected all elements of %s to be zero", name)
+		}
+	}
+}
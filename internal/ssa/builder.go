@@ -2,14 +2,12 @@
 package ssa
 
 import (
-	"fmt"
 	"go/ast"
 	"go/importer"
 	"go/parser"
 	"go/token"
 	"go/types"
 
-	"golang.org/x/tools/go/packages"
 	"golang.org/x/tools/go/ssa"
 	"golang.org/x/tools/go/ssa/ssautil"
 )
@@ -28,40 +26,25 @@ func NewBuilder() *Builder {
 
 // ParseAndBuildSSA парсит исходный код Go и создаёт SSA представление
 // Возвращает SSA программу и функцию по имени
+// Исходник разбирается как файл main.go пакета main; для загрузки настоящих
+// пакетов и модулей используйте Load. Ошибки разбора и проверки типов
+// возвращаются как *BuildError со всеми ошибками и их позициями
 func (b *Builder) ParseAndBuildSSA(source string, funcName string) (*ssa.Function, error) {
-	fset := token.NewFileSet()
-
-	file, err := parser.ParseFile(fset, "main.go", source, parser.ParseComments)
+	file, err := parser.ParseFile(b.fset, "main.go", source, parser.ParseComments|parser.AllErrors)
 	if err != nil {
-		panic("parser error")
+		return nil, parseError(err)
 	}
 	files := []*ast.File{file}
 
-	pkg := types.NewPackage("homework1/main.go", "main")
-
-	lprog, _, err := ssautil.BuildPackage(
-		&types.Config{Importer: importer.Default()}, fset, pkg, files, ssa.SanityCheckFunctions)
-	if err != nil {
-		panic("type error in package")
-	}
+	pkg := types.NewPackage("main", "main")
 
-	cfg := &packages.Config{Mode: packages.LoadSyntax}
-	initial, err := packages.Load(cfg, "main")
+	typeErrs := &typeErrors{fset: b.fset}
+	conf := &types.Config{Importer: importer.Default(), Error: typeErrs.add}
+	ssaPkg, _, err := ssautil.BuildPackage(conf, b.fset, pkg, files, ssa.SanityCheckFunctions|ssa.InstantiateGenerics)
 	if err != nil {
-		panic("error in package load")
-	}
-	prog, _ := ssautil.Packages(initial, ssa.PrintPackages)
-	_ = prog
-	lprog.Build()
-
-	for _, p := range prog.AllPackages() {
-		if fnObj := p.Func(funcName); fnObj != nil {
-			return fnObj, nil
-		}
-	}
-	if fnObj := lprog.Func(funcName); fnObj != nil {
-		return fnObj, nil
+		return nil, &BuildError{Stage: StageTypeCheck, Errors: typeErrs.errors, Err: err}
 	}
 
-	return nil, fmt.Errorf("function %s not found", funcName)
+	prog := &Program{Prog: ssaPkg.Prog, Packages: []*ssa.Package{ssaPkg}}
+	return prog.Func(funcName)
 }
@@ -0,0 +1,62 @@
+package ssa
+
+import "testing"
+
+func TestParseAndBuildSSA(t *testing.T) {
+	source := `package main
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+`
+	fn, err := NewBuilder().ParseAndBuildSSA(source, "abs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fn.Blocks) != 3 {
+		t.Errorf("Expected 3 blocks in abs, got %d", len(fn.Blocks))
+	}
+}
+
+func TestLoadPackageWithDependencies(t *testing.T) {
+	prog, err := NewBuilder().Load("testdata/loader", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prog.Packages) != 1 {
+		t.Fatalf("Expected 1 loaded package, got %d", len(prog.Packages))
+	}
+
+	for _, name := range []string{
+		"sum",
+		"example.com/loader.main",
+		// Зависимость, не заданная шаблоном
+		"New",
+		"example.com/loader/list.New",
+	} {
+		fn, err := prog.Func(name)
+		if err != nil {
+			t.Errorf("Expected to find %s: %v", name, err)
+			continue
+		}
+		if len(fn.Blocks) == 0 {
+			t.Errorf("Expected %s to have a body", name)
+		}
+	}
+	if _, err := prog.Func("noSuchFunction"); err == nil {
+		t.Error("Expected an error for a missing function")
+	}
+}
+
+func TestLoadModulePattern(t *testing.T) {
+	prog, err := NewBuilder().Load("testdata/loader", "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prog.Packages) != 2 {
+		t.Errorf("Expected both packages of the module, got %d", len(prog.Packages))
+	}
+}
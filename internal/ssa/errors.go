@@ -0,0 +1,121 @@
+package ssa
+
+import (
+	"errors"
+	"fmt"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Стадии построения SSA, на которых может возникнуть ошибка
+const (
+	StageParse     = "parse"
+	StageTypeCheck = "typecheck"
+	StageLoad      = "load"
+)
+
+// SourceError - ошибка во входном коде с позицией file:line:col
+type SourceError struct {
+	Pos token.Position
+	Msg string
+}
+
+// Error возвращает сообщение в формате "file:line:col: msg"
+func (e SourceError) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Msg
+	}
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// BuildError - ошибка построения SSA: стадия, все найденные ошибки исходного
+// кода и исходная ошибка, доступная через errors.Unwrap
+type BuildError struct {
+	Stage  string
+	Errors []SourceError
+	Err    error
+}
+
+// Error возвращает первую ошибку исходника и число остальных
+func (e *BuildError) Error() string {
+	switch len(e.Errors) {
+	case 0:
+		return e.Stage + ": " + e.Err.Error()
+	case 1:
+		return e.Stage + ": " + e.Errors[0].Error()
+	default:
+		return fmt.Sprintf("%s: %s (and %d more)", e.Stage, e.Errors[0].Error(), len(e.Errors)-1)
+	}
+}
+
+// Unwrap возвращает исходную ошибку
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// parseError оборачивает ошибку парсера; scanner.ErrorList раскладывается по позициям
+func parseError(err error) *BuildError {
+	res := &BuildError{Stage: StageParse, Err: err}
+	var list scanner.ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			res.Errors = append(res.Errors, SourceError{Pos: e.Pos, Msg: e.Msg})
+		}
+	}
+	return res
+}
+
+// typeErrors собирает ошибки проверки типов через types.Config.Error
+type typeErrors struct {
+	fset   *token.FileSet
+	errors []SourceError
+}
+
+func (te *typeErrors) add(err error) {
+	var typeErr types.Error
+	if errors.As(err, &typeErr) {
+		te.errors = append(te.errors, SourceError{Pos: te.fset.Position(typeErr.Pos), Msg: typeErr.Msg})
+		return
+	}
+	te.errors = append(te.errors, SourceError{Msg: err.Error()})
+}
+
+// packageErrors собирает ошибки загруженных пакетов и их зависимостей
+func packageErrors(pkgs []*packages.Package) []SourceError {
+	var res []SourceError
+	packages.Visit(pkgs, nil, func(p *packages.Package) {
+		for _, e := range p.Errors {
+			res = append(res, SourceError{Pos: parsePosition(e.Pos), Msg: e.Msg})
+		}
+	})
+	return res
+}
+
+// parsePosition разбирает позицию вида "file:line:col" или "file:line"
+func parsePosition(pos string) token.Position {
+	var res token.Position
+	parts := strings.Split(pos, ":")
+	var nums []int
+	for len(parts) > 1 && len(nums) < 2 {
+		n, err := strconv.Atoi(parts[len(parts)-1])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		parts = parts[:len(parts)-1]
+	}
+	if len(nums) == 0 {
+		return res
+	}
+	res.Filename = strings.Join(parts, ":")
+	res.Line = nums[0]
+	if len(nums) > 1 {
+		res.Column = nums[1]
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package ssa
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorPosition(t *testing.T) {
+	source := "package main\n\nfunc f( {\n}\n"
+	_, err := NewBuilder().ParseAndBuildSSA(source, "f")
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected *BuildError, got %v", err)
+	}
+	if buildErr.Stage != StageParse || len(buildErr.Errors) == 0 {
+		t.Fatalf("Expected parse errors, got %v", buildErr)
+	}
+	if pos := buildErr.Errors[0].Pos; pos.Filename != "main.go" || pos.Line != 3 {
+		t.Errorf("Expected error at main.go:3, got %v", pos)
+	}
+}
+
+func TestTypeErrorsAreCollected(t *testing.T) {
+	source := `package main
+
+func f(x int) int {
+	return x + y
+}
+
+func g() string {
+	return 1
+}
+`
+	_, err := NewBuilder().ParseAndBuildSSA(source, "f")
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected *BuildError, got %v", err)
+	}
+	if buildErr.Stage != StageTypeCheck || len(buildErr.Errors) != 2 {
+		t.Fatalf("Expected 2 type errors, got %v", buildErr.Errors)
+	}
+	if got := buildErr.Errors[0].Error(); got != "main.go:4:13: undefined: y" {
+		t.Errorf("Unexpected first error %q", got)
+	}
+	if buildErr.Errors[1].Pos.Line != 8 {
+		t.Errorf("Expected second error on line 8, got %v", buildErr.Errors[1])
+	}
+	if !strings.Contains(err.Error(), "(and 1 more)") {
+		t.Errorf("Expected the error count in %q", err.Error())
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := NewBuilder().Load("testdata/broken", ".")
+
+	var buildErr *BuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("Expected *BuildError, got %v", err)
+	}
+	if buildErr.Stage != StageLoad || len(buildErr.Errors) != 1 {
+		t.Fatalf("Expected a single load error, got %v", buildErr.Errors)
+	}
+	pos := buildErr.Errors[0].Pos
+	if !strings.HasSuffix(pos.Filename, "broken.go") || pos.Line != 4 || pos.Column != 13 {
+		t.Errorf("Expected error at broken.go:4:13, got %v", pos)
+	}
+}
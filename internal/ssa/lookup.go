@@ -0,0 +1,152 @@
+package ssa
+
+import (
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// QualifiedName возвращает полное имя функции, по которому её находит Func:
+//   - функция пакета:     "path/pkg.F"
+//   - метод:              "path/pkg.(*T).M" или "path/pkg.T.M"
+//   - замыкание:          имя объемлющей функции с суффиксом "$1", "$1$2", ...
+//   - инстанцирование:    "path/pkg.F[int, path/other.T]"
+func QualifiedName(fn *ssa.Function) string {
+	if parent := fn.Parent(); parent != nil {
+		return QualifiedName(parent) + strings.TrimPrefix(fn.Name(), parent.Name())
+	}
+	path := funcPackagePath(fn)
+	name := fn.Name()
+	if recv := fn.Signature.Recv(); recv != nil {
+		qualifier := func(p *types.Package) string {
+			if p.Path() == path {
+				return ""
+			}
+			return p.Path()
+		}
+		recvName := types.TypeString(recv.Type(), qualifier)
+		if strings.HasPrefix(recvName, "*") {
+			recvName = "(" + recvName + ")"
+		}
+		// Аргументы типа метода входят в тип получателя
+		if origin := fn.Origin(); origin != nil {
+			name = origin.Name()
+		}
+		name = recvName + "." + name
+	} else if origin := fn.Origin(); origin != nil {
+		var args []string
+		for _, arg := range fn.TypeArgs() {
+			args = append(args, types.TypeString(arg, nil))
+		}
+		name = origin.Name() + "[" + strings.Join(args, ", ") + "]"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// funcPackage возвращает пакет, к которому относится функция
+// (для инстанцирований и обёрток - пакет исходной функции)
+func funcPackage(fn *ssa.Function) *types.Package {
+	switch {
+	case fn.Pkg != nil:
+		return fn.Pkg.Pkg
+	case fn.Origin() != nil:
+		return funcPackage(fn.Origin())
+	case fn.Object() != nil:
+		return fn.Object().Pkg()
+	default:
+		return nil
+	}
+}
+
+// funcPackagePath возвращает путь пакета функции ("" - функция вне пакетов)
+func funcPackagePath(fn *ssa.Function) string {
+	if pkg := funcPackage(fn); pkg != nil {
+		return pkg.Path()
+	}
+	return ""
+}
+
+// Functions возвращает все функции программы, включая методы, замыкания,
+// инстанцирования и синтетические обёртки, в порядке полных имён
+func (p *Program) Functions() []*ssa.Function {
+	var res []*ssa.Function
+	for fn := range ssautil.AllFunctions(p.Prog) {
+		res = append(res, fn)
+	}
+	sort.Slice(res, func(i, j int) bool { return QualifiedName(res[i]) < QualifiedName(res[j]) })
+	return res
+}
+
+// Func ищет функцию по имени. Полное имя (см. QualifiedName) или имя в формате
+// ssa.Function.String() задают функцию однозначно. Имя без пути пакета ("F",
+// "(*T).M", "F$1", "F[int]") или с именем пакета вместо пути ("pkg.(*T).M",
+// "pkg.F[int]") ищется сначала в загруженных пакетах, затем во всей программе
+// и должно быть однозначным. Пробелы в именах не учитываются
+func (p *Program) Func(name string) (*ssa.Function, error) {
+	if p.index == nil {
+		p.buildIndex()
+	}
+	key := normalizeName(name)
+	if fns := p.index[key]; len(fns) == 1 {
+		return fns[0], nil
+	}
+
+	var local []*ssa.Function
+	all := p.short[key]
+	for _, fn := range all {
+		for _, pkg := range p.Packages {
+			if pkg.Pkg.Path() == funcPackagePath(fn) {
+				local = append(local, fn)
+			}
+		}
+	}
+	for _, candidates := range [][]*ssa.Function{local, all} {
+		switch len(candidates) {
+		case 0:
+			continue
+		case 1:
+			return candidates[0], nil
+		default:
+			var names []string
+			for _, fn := range candidates {
+				names = append(names, QualifiedName(fn))
+			}
+			return nil, fmt.Errorf("function %s is ambiguous: %s", name, strings.Join(names, ", "))
+		}
+	}
+	return nil, fmt.Errorf("function %s not found", name)
+}
+
+func (p *Program) buildIndex() {
+	p.index = make(map[string][]*ssa.Function)
+	p.short = make(map[string][]*ssa.Function)
+	for _, fn := range p.Functions() {
+		qualified := normalizeName(QualifiedName(fn))
+		p.index[qualified] = append(p.index[qualified], fn)
+		if str := normalizeName(fn.String()); str != qualified {
+			p.index[str] = append(p.index[str], fn)
+		}
+
+		pkg := funcPackage(fn)
+		if pkg == nil {
+			continue
+		}
+		short := strings.TrimPrefix(qualified, normalizeName(pkg.Path())+".")
+		p.short[short] = append(p.short[short], fn)
+		if named := pkg.Name() + "." + short; named != qualified {
+			p.short[named] = append(p.short[named], fn)
+		}
+	}
+}
+
+// normalizeName убирает пробелы, чтобы "F[int, bool]" и "F[int,bool]" совпадали
+func normalizeName(name string) string {
+	return strings.ReplaceAll(name, " ", "")
+}
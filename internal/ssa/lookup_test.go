@@ -0,0 +1,90 @@
+package ssa
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestQualifiedLookup(t *testing.T) {
+	prog, err := NewBuilder().Load("testdata/loader", ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, want := range map[string]string{
+		"example.com/loader/list.(*List).Push":  "example.com/loader/list.(*List).Push",
+		"(*List).Push":                          "example.com/loader/list.(*List).Push",
+		"example.com/loader/list.List.Len":      "example.com/loader/list.List.Len",
+		"List.Len$1":                            "example.com/loader/list.List.Len$1",
+		"main$1":                                "example.com/loader.main$1",
+		"list.Map[int, bool]":                   "example.com/loader/list.Map[int, bool]",
+		"list.(*List).Push":                     "example.com/loader/list.(*List).Push",
+		"list.Nope":                             "",
+		"Map[int,bool]":                         "example.com/loader/list.Map[int, bool]",
+		"example.com/loader/list.Map[int bool]": "example.com/loader/list.Map[int, bool]",
+		"(*example.com/loader/list.List).Each":  "example.com/loader/list.(*List).Each",
+		"Map":                                   "example.com/loader/list.Map",
+	} {
+		fn, err := prog.Func(name)
+		if want == "" {
+			if err == nil {
+				t.Errorf("Expected %s not to be found, got %s", name, QualifiedName(fn))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected to find %s: %v", name, err)
+			continue
+		}
+		if got := QualifiedName(fn); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+
+	// init есть в обоих пакетах, но загружен по шаблону только главный
+	if fn, err := prog.Func("init"); err != nil || QualifiedName(fn) != "example.com/loader.init" {
+		t.Errorf("Expected init of the loaded package, got %v", err)
+	}
+}
+
+func TestFunctionsEnumeration(t *testing.T) {
+	prog, err := NewBuilder().Load("testdata/loader", "./...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for _, fn := range prog.Functions() {
+		names[QualifiedName(fn)] = true
+	}
+	for _, want := range []string{
+		"example.com/loader.sum",
+		"example.com/loader.main$1",
+		"example.com/loader/list.New",
+		"example.com/loader/list.(*List).Each",
+		"example.com/loader/list.Map[int, bool]",
+	} {
+		if !names[want] {
+			t.Errorf("Expected %s among functions", want)
+		}
+	}
+	if _, err := prog.Func("init"); err == nil || !strings.Contains(err.Error(), "ambiguous") {
+		t.Errorf("Expected init to be ambiguous when both packages are loaded, got %v", err)
+	}
+}
+
+func TestParseAndBuildSSAMethod(t *testing.T) {
+	source := `package main
+
+type Counter struct{ n int }
+
+func (c *Counter) Inc() {
+	add := func(d int) { c.n += d }
+	add(1)
+}
+`
+	for _, name := range []string{"(*Counter).Inc", "(*Counter).Inc$1"} {
+		if _, err := NewBuilder().ParseAndBuildSSA(source, name); err != nil {
+			t.Errorf("Expected to find %s: %v", name, err)
+		}
+	}
+}
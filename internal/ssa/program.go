@@ -0,0 +1,67 @@
+package ssa
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+	"golang.org/x/tools/go/ssa"
+	"golang.org/x/tools/go/ssa/ssautil"
+)
+
+// loadMode - всё, что нужно для построения SSA пакетов и их зависимостей из исходников
+const loadMode = packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+	packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedTypesSizes |
+	packages.NeedSyntax | packages.NeedTypesInfo
+
+// Program - SSA программа, построенная по загруженным пакетам и всем их зависимостям
+type Program struct {
+	Prog     *ssa.Program
+	Packages []*ssa.Package // пакеты, заданные шаблонами загрузки
+
+	index map[string][]*ssa.Function // нормализованное полное имя -> функции, см. Func
+	short map[string][]*ssa.Function // имя без пути пакета или с именем пакета -> функции
+}
+
+// Load загружает пакеты по шаблонам go/packages относительно каталога dir
+// (пустая строка - текущий каталог) и строит SSA для них и их зависимостей.
+// Шаблоны - каталоги ("./homework1/examples"), пути импорта или шаблоны
+// модуля ("./..."); без шаблонов загружается пакет в dir. Ошибки пакетов
+// и их зависимостей возвращаются как *BuildError стадии StageLoad
+func (b *Builder) Load(dir string, patterns ...string) (*Program, error) {
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	cfg := &packages.Config{Mode: loadMode, Dir: dir, Fset: b.fset}
+	initial, err := packages.Load(cfg, patterns...)
+	if err != nil {
+		return nil, &BuildError{Stage: StageLoad, Err: fmt.Errorf("load %s: %w", strings.Join(patterns, " "), err)}
+	}
+	if errs := packageErrors(initial); len(errs) > 0 {
+		return nil, &BuildError{
+			Stage:  StageLoad,
+			Errors: errs,
+			Err:    fmt.Errorf("load %s: packages contain errors", strings.Join(patterns, " ")),
+		}
+	}
+
+	prog, pkgs := ssautil.AllPackages(initial, ssa.InstantiateGenerics)
+	prog.Build()
+	res := &Program{Prog: prog}
+	for _, p := range pkgs {
+		if p != nil {
+			res.Packages = append(res.Packages, p)
+		}
+	}
+	return res, nil
+}
+
+// Package возвращает SSA пакет по пути импорта или nil
+func (p *Program) Package(path string) *ssa.Package {
+	for _, pkg := range p.Prog.AllPackages() {
+		if pkg.Pkg.Path() == path {
+			return pkg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,5 @@
+package broken
+
+func f(x int) int {
+	return x + y
+}
@@ -0,0 +1,42 @@
+package list
+
+type Node struct {
+	Value int
+	Next  *Node
+}
+
+type List struct {
+	Head *Node
+}
+
+func New(values ...int) *List {
+	l := &List{}
+	for i := len(values) - 1; i >= 0; i-- {
+		l.Head = &Node{Value: values[i], Next: l.Head}
+	}
+	return l
+}
+
+func (l *List) Push(v int) {
+	l.Head = &Node{Value: v, Next: l.Head}
+}
+
+func (l List) Len() int {
+	n := 0
+	l.Each(func(int) { n++ })
+	return n
+}
+
+func (l *List) Each(f func(int)) {
+	for n := l.Head; n != nil; n = n.Next {
+		f(n.Value)
+	}
+}
+
+func Map[T, U any](xs []T, f func(T) U) []U {
+	res := make([]U, 0, len(xs))
+	for _, x := range xs {
+		res = append(res, f(x))
+	}
+	return res
+}
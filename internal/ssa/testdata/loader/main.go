@@ -0,0 +1,19 @@
+package main
+
+import "example.com/loader/list"
+
+func sum(l *list.List) int {
+	total := 0
+	for n := l.Head; n != nil; n = n.Next {
+		total += n.Value
+	}
+	return total
+}
+
+func main() {
+	l := list.New(1, 2, 3)
+	l.Push(0)
+	println(sum(l), l.Len())
+	positive := list.Map([]int{1, -2}, func(x int) bool { return x > 0 })
+	println(len(positive))
+}
@@ -123,6 +123,7 @@ type BinaryOperation struct {
 	Left     SymbolicExpression
 	Right    SymbolicExpression
 	Operator BinaryOperator
+	Value    SymbolicExpression // записываемое значение для STORE
 }
 
 // NewBinaryOperation создаёт новую бинарную операцию
@@ -139,6 +140,19 @@ func NewBinaryOperation(left, right SymbolicExpression, op BinaryOperator) *Bina
 	}
 }
 
+// NewStoreOperation создаёт запись value в массив arr по индексу index: arr[index] = value
+func NewStoreOperation(arr, index, value SymbolicExpression) *BinaryOperation {
+	if arr.Type() != ArrayType || index.Type() != IntType {
+		panic("type error")
+	}
+	return &BinaryOperation{
+		Left:     arr,
+		Right:    index,
+		Operator: STORE,
+		Value:    value,
+	}
+}
+
 // Type возвращает результирующий тип операции
 func (bo *BinaryOperation) Type() ExpressionType {
 	// Определить результирующий тип на основе операции и типов операндов
@@ -196,7 +210,9 @@ func (bo *BinaryOperation) Type() ExpressionType {
 		return BoolType
 
 	case SELECT:
-		return bo.Left.(*SymbolicVariable).InnerType.ExprTy
+		return InnerTypeOf(bo).ExprTy
+	case STORE:
+		return ArrayType
 
 	case FIELD_ACCESS:
 		return bo.Left.Type()
@@ -211,6 +227,9 @@ func (bo *BinaryOperation) Type() ExpressionType {
 // String возвращает строковое представление операции
 func (bo *BinaryOperation) String() string {
 	// Формат: "(left operator right)"
+	if bo.Operator == STORE {
+		return "(" + bo.Left.String() + "[" + bo.Right.String() + "]=" + bo.Value.String() + ")"
+	}
 	return "(" + bo.Left.String() + bo.Operator.String() + bo.Right.String() + ")"
 }
 
@@ -467,25 +486,11 @@ func Type2Sort(ctx *z3.Context, ty *InnerType) z3.Sort {
 		return ctx.BoolSort()
 	case ArrayType:
 		return ctx.ArraySort(ctx.IntSort(), Type2Sort(ctx, ty.InnerTy))
-	case ObjectType:
-		panic("ObjectType in Type2Sort")
-
-	default:
-		panic("unknown type")
-	}
-}
-
-func Type2Sort2(ctx *z3.Context, expr SymbolicExpression) z3.Sort {
-	switch expr.Type() {
-	case IntType:
+	case RefType:
+		// Ссылки представляются адресами
 		return ctx.IntSort()
-	case BoolType:
-		return ctx.BoolSort()
-	case ArrayType:
-		innerT := expr.(*SymbolicVariable).InnerType
-		return ctx.ArraySort(ctx.IntSort(), Type2Sort(ctx, &innerT))
 	case ObjectType:
-		panic("ObjectType in Type2Sort2")
+		panic("ObjectType in Type2Sort")
 
 	default:
 		panic("unknown type")
@@ -557,42 +562,68 @@ const (
 	Primitive MemType = iota
 	Object
 	Array
+	Map
 )
 
+// Ref представляет ссылку: символьный адрес (IntType) в куче объектов типа StructName.
+// Выделенные программой объекты получают положительные адреса-константы,
+// входные указатели - символьные адреса, поэтому возможный алиасинг решает solver
 type Ref struct {
-	Expr       SymbolicExpression
+	Addr       SymbolicExpression
 	MemTy      MemType
-	ObjectAddr int
-	ArrayAddr  int
 	StructName string
 }
 
+// NilAddr - адрес, которым представляется nil
+const NilAddr = 0
+
+// NewNilRef создаёт nil-указатель на объект типа typeName
+func NewNilRef(typeName string) *Ref {
+	return &Ref{Addr: NewIntConstant(NilAddr), MemTy: Object, StructName: typeName}
+}
+
+// IsNil проверяет, что ссылка заведомо nil
+func (ref *Ref) IsNil() bool {
+	c, ok := ref.Addr.(*IntConstant)
+	return ok && c.Value == NilAddr
+}
+
+// NewIsNil строит условие "указатель expr равен nil"
+func NewIsNil(expr SymbolicExpression) *BinaryOperation {
+	return NewBinaryOperation(expr, NewNilRef(""), EQ)
+}
+
 func (ref *Ref) Type() ExpressionType {
 	return RefType
 }
 
 func (ref *Ref) String() string {
-	return "&" + ref.Expr.String()
+	if ref.IsNil() {
+		return "nil"
+	}
+	return "&" + ref.StructName + "@" + ref.Addr.String()
 }
 
 func (ref *Ref) Accept(visitor Visitor) interface{} {
 	return visitor.VisitRef(ref)
 }
 
+// FieldAccess представляет чтение поля объекта: select(Heap, Addr),
+// где Heap - массив поля FieldIdx всех объектов типа StructName
 type FieldAccess struct {
-	Obj        SymbolicExpression
+	Heap       SymbolicExpression
+	Addr       SymbolicExpression
 	FieldIdx   int
-	Key        SymbolicExpression
 	StructName string
 	InnerTy    InnerType
 }
 
 // NewFieldAccess создаёт новое обращение к полю объекта
-func NewFieldAccess(obj SymbolicExpression, Idx int, key SymbolicExpression, structName string, innerTy InnerType) *FieldAccess {
+func NewFieldAccess(heap SymbolicExpression, addr SymbolicExpression, idx int, structName string, innerTy InnerType) *FieldAccess {
 	return &FieldAccess{
-		Obj:        obj,
-		FieldIdx:   Idx,
-		Key:        key,
+		Heap:       heap,
+		Addr:       addr,
+		FieldIdx:   idx,
 		StructName: structName,
 		InnerTy:    innerTy,
 	}
@@ -600,12 +631,12 @@ func NewFieldAccess(obj SymbolicExpression, Idx int, key SymbolicExpression, str
 
 // Type возвращает тип поля
 func (fa *FieldAccess) Type() ExpressionType {
-	return fa.Obj.Type()
+	return fa.InnerTy.ExprTy
 }
 
-// String возвращает строковое представление доступа к элементу массива
+// String возвращает строковое представление доступа к полю
 func (fa *FieldAccess) String() string {
-	return "(" + fa.Obj.String() + ")"
+	return "(" + fa.Heap.String() + "[" + fa.Addr.String() + "])"
 }
 
 // Accept реализует Visitor pattern
@@ -613,34 +644,119 @@ func (fa *FieldAccess) Accept(visitor Visitor) interface{} {
 	return visitor.VisitFieldAccess(fa)
 }
 
+// FieldAssign представляет запись поля объекта: store(Heap, Addr, Value).
+// Если задан Index, поле - массив и записывается один его элемент:
+// store(Heap, Addr, store(select(Heap, Addr), Index, Value)).
+// Значение выражения - новый массив поля
 type FieldAssign struct {
-	Obj        SymbolicExpression
+	Heap       SymbolicExpression
+	Addr       SymbolicExpression
 	FieldIdx   int
+	Index      SymbolicExpression
 	Value      SymbolicExpression
 	StructName string
 }
 
-// NewFieldAccess создаёт новое обращение к полю объекта
-func NewFieldAssign(obj SymbolicExpression, Idx int, v SymbolicExpression, structName string) *FieldAssign {
+// NewFieldAssign создаёт новую запись в поле объекта
+func NewFieldAssign(heap SymbolicExpression, addr SymbolicExpression, idx int, v SymbolicExpression, structName string) *FieldAssign {
 	return &FieldAssign{
-		Obj:        obj,
-		FieldIdx:   Idx,
+		Heap:       heap,
+		Addr:       addr,
+		FieldIdx:   idx,
 		Value:      v,
 		StructName: structName,
 	}
 }
 
-// Type возвращает тип поля
+// NewElementAssign создаёт запись элемента index в поле-массив объекта
+func NewElementAssign(heap SymbolicExpression, addr SymbolicExpression, idx int, index SymbolicExpression, v SymbolicExpression, structName string) *FieldAssign {
+	return &FieldAssign{
+		Heap:       heap,
+		Addr:       addr,
+		FieldIdx:   idx,
+		Index:      index,
+		Value:      v,
+		StructName: structName,
+	}
+}
+
+// Type возвращает тип массива поля
 func (fa *FieldAssign) Type() ExpressionType {
-	return fa.Value.Type()
+	return ArrayType
 }
 
-// String возвращает строковое представление переменной
+// String возвращает строковое представление записи
 func (fa *FieldAssign) String() string {
-	return "(" + fa.Obj.String() + "." + strconv.Itoa(fa.FieldIdx) + "=" + fa.Value.String() + ")"
+	if fa.Index != nil {
+		return "(" + fa.Heap.String() + "[" + fa.Addr.String() + "]." + strconv.Itoa(fa.FieldIdx) + "[" + fa.Index.String() + "]=" + fa.Value.String() + ")"
+	}
+	return "(" + fa.Heap.String() + "[" + fa.Addr.String() + "]." + strconv.Itoa(fa.FieldIdx) + "=" + fa.Value.String() + ")"
 }
 
 // Accept реализует Visitor pattern
 func (fa *FieldAssign) Accept(visitor Visitor) interface{} {
 	return visitor.VisitFieldAssign(fa)
 }
+
+// InnerTypeOf возвращает полный тип выражения (для массивов - вместе с типом элементов)
+func InnerTypeOf(expr SymbolicExpression) InnerType {
+	switch e := expr.(type) {
+	case *SymbolicVariable:
+		if e.ExprType == ArrayType {
+			elemTy := e.InnerType
+			return InnerType{ExprTy: ArrayType, InnerTy: &elemTy}
+		}
+		return InnerType{ExprTy: e.ExprType}
+	case *FieldAccess:
+		return e.InnerTy
+	case *ArrayConstant:
+		valueTy := InnerTypeOf(e.Value)
+		return InnerType{ExprTy: ArrayType, InnerTy: &valueTy}
+	case *FieldAssign:
+		valueTy := InnerTypeOf(e.Value)
+		if e.Index != nil {
+			valueTy = InnerType{ExprTy: ArrayType, InnerTy: &valueTy}
+		}
+		return InnerType{ExprTy: ArrayType, InnerTy: &valueTy}
+	case *TernaryOperation:
+		return InnerTypeOf(e.TrueExpr)
+	case *BinaryOperation:
+		switch e.Operator {
+		case SELECT:
+			if arrTy := InnerTypeOf(e.Left); arrTy.InnerTy != nil {
+				return *arrTy.InnerTy
+			}
+			panic("element type of " + e.Left.String() + " is unknown")
+		case STORE:
+			return InnerTypeOf(e.Left)
+		}
+		return InnerType{ExprTy: e.Type()}
+	default:
+		return InnerType{ExprTy: expr.Type()}
+	}
+}
+
+// ArrayConstant представляет массив, все элементы которого равны Value
+type ArrayConstant struct {
+	Value SymbolicExpression
+}
+
+// NewArrayConstant создаёт массив, заполненный значением value
+func NewArrayConstant(value SymbolicExpression) *ArrayConstant {
+	return &ArrayConstant{Value: value}
+}
+
+// Type возвращает тип выражения (всегда массив)
+func (ac *ArrayConstant) Type() ExpressionType {
+	return ArrayType
+}
+
+// String возвращает строковое представление массива
+func (ac *ArrayConstant) String() string {
+	return "[" + ac.Value.String() + "...]"
+}
+
+// Accept реализует Visitor pattern
+func (ac *ArrayConstant) Accept(visitor Visitor) interface{} {
+	return visitor.VisitArrayConstant(ac)
+}
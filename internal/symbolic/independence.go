@@ -0,0 +1,221 @@
+package symbolic
+
+import "sort"
+
+// VariableUnionFind - система непересекающихся множеств над символьными переменными.
+// Переменные отождествляются по имени
+type VariableUnionFind struct {
+	parent map[string]string
+	rank   map[string]int
+}
+
+// NewVariableUnionFind создаёт пустую систему множеств
+func NewVariableUnionFind() *VariableUnionFind {
+	return &VariableUnionFind{
+		parent: make(map[string]string),
+		rank:   make(map[string]int),
+	}
+}
+
+// Add добавляет переменную отдельным множеством, если её ещё нет
+func (uf *VariableUnionFind) Add(v *SymbolicVariable) {
+	if _, ok := uf.parent[v.Name]; !ok {
+		uf.parent[v.Name] = v.Name
+	}
+}
+
+// Find возвращает представителя множества, содержащего переменную
+func (uf *VariableUnionFind) Find(v *SymbolicVariable) string {
+	uf.Add(v)
+	return uf.find(v.Name)
+}
+
+func (uf *VariableUnionFind) find(name string) string {
+	root := name
+	for uf.parent[root] != root {
+		root = uf.parent[root]
+	}
+	// Сжатие путей
+	for uf.parent[name] != root {
+		name, uf.parent[name] = uf.parent[name], root
+	}
+	return root
+}
+
+// Union объединяет множества двух переменных
+func (uf *VariableUnionFind) Union(a, b *SymbolicVariable) {
+	ra, rb := uf.Find(a), uf.Find(b)
+	if ra == rb {
+		return
+	}
+	switch {
+	case uf.rank[ra] < uf.rank[rb]:
+		uf.parent[ra] = rb
+	case uf.rank[ra] > uf.rank[rb]:
+		uf.parent[rb] = ra
+	default:
+		uf.parent[rb] = ra
+		uf.rank[ra]++
+	}
+}
+
+// Same проверяет, лежат ли переменные в одном множестве
+func (uf *VariableUnionFind) Same(a, b *SymbolicVariable) bool {
+	return uf.Find(a) == uf.Find(b)
+}
+
+// PartitionConstraints разбивает ограничения на независимые группы: ограничения
+// попадают в одну группу, если связаны цепочкой общих свободных переменных.
+// Ограничения без переменных добавляются в каждую группу, так как могут сделать
+// невыполнимым любой запрос. Порядок ограничений внутри группы сохраняется
+func PartitionConstraints(constraints []SymbolicExpression) [][]SymbolicExpression {
+	uf := NewVariableUnionFind()
+	vars := make([][]*SymbolicVariable, len(constraints))
+	for i, c := range constraints {
+		vars[i] = FreeVariables(c)
+		for j := range vars[i] {
+			uf.Add(vars[i][j])
+			if j > 0 {
+				uf.Union(vars[i][0], vars[i][j])
+			}
+		}
+	}
+
+	var ground []SymbolicExpression
+	groups := make(map[string][]SymbolicExpression)
+	var order []string
+	for i, c := range constraints {
+		if len(vars[i]) == 0 {
+			ground = append(ground, c)
+			continue
+		}
+		root := uf.Find(vars[i][0])
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], c)
+	}
+
+	var res [][]SymbolicExpression
+	for _, root := range order {
+		res = append(res, append(append([]SymbolicExpression{}, ground...), groups[root]...))
+	}
+	if len(res) == 0 && len(ground) > 0 {
+		res = append(res, ground)
+	}
+	return res
+}
+
+// SliceConstraints возвращает ограничения, связанные с query через общие переменные
+// (включая ограничения без переменных). Сам query в результат не входит
+func SliceConstraints(constraints []SymbolicExpression, query SymbolicExpression) []SymbolicExpression {
+	uf := NewVariableUnionFind()
+	queryVars := FreeVariables(query)
+	for j := range queryVars {
+		uf.Add(queryVars[j])
+		if j > 0 {
+			uf.Union(queryVars[0], queryVars[j])
+		}
+	}
+	vars := make([][]*SymbolicVariable, len(constraints))
+	for i, c := range constraints {
+		vars[i] = FreeVariables(c)
+		for j := range vars[i] {
+			uf.Add(vars[i][j])
+			if j > 0 {
+				uf.Union(vars[i][0], vars[i][j])
+			}
+		}
+	}
+
+	var res []SymbolicExpression
+	for i, c := range constraints {
+		if len(vars[i]) == 0 || (len(queryVars) > 0 && uf.Same(vars[i][0], queryVars[0])) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// FreeVariables возвращает свободные переменные выражения, упорядоченные по имени
+func FreeVariables(expr SymbolicExpression) []*SymbolicVariable {
+	collector := &variableCollector{vars: make(map[string]*SymbolicVariable), visited: make(map[SymbolicExpression]bool)}
+	expr.Accept(collector)
+
+	res := make([]*SymbolicVariable, 0, len(collector.vars))
+	for _, v := range collector.vars {
+		res = append(res, v)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+// variableCollector обходит выражение и собирает встреченные переменные.
+// Общие подвыражения обходятся один раз
+type variableCollector struct {
+	vars    map[string]*SymbolicVariable
+	visited map[SymbolicExpression]bool
+}
+
+func (vc *variableCollector) visitAll(exprs ...SymbolicExpression) interface{} {
+	for _, e := range exprs {
+		if e != nil && !vc.visited[e] {
+			vc.visited[e] = true
+			e.Accept(vc)
+		}
+	}
+	return nil
+}
+
+func (vc *variableCollector) VisitVariable(expr *SymbolicVariable) interface{} {
+	vc.vars[expr.Name] = expr
+	return nil
+}
+
+func (vc *variableCollector) VisitIntConstant(expr *IntConstant) interface{} {
+	return nil
+}
+
+func (vc *variableCollector) VisitBoolConstant(expr *BoolConstant) interface{} {
+	return nil
+}
+
+func (vc *variableCollector) VisitBinaryOperation(expr *BinaryOperation) interface{} {
+	return vc.visitAll(expr.Left, expr.Right, expr.Value)
+}
+
+func (vc *variableCollector) VisitLogicalOperation(expr *LogicalOperation) interface{} {
+	return vc.visitAll(expr.Operands...)
+}
+
+func (vc *variableCollector) VisitTernaryOperation(expr *TernaryOperation) interface{} {
+	return vc.visitAll(expr.Condition, expr.TrueExpr, expr.FalseExpr)
+}
+
+func (vc *variableCollector) VisitUnaryOperation(expr *UnaryOperation) interface{} {
+	return vc.visitAll(expr.Expr)
+}
+
+func (vc *variableCollector) VisitFunction(expr *Function) interface{} {
+	return nil
+}
+
+func (vc *variableCollector) VisitFunctionCall(expr *FunctionCall) interface{} {
+	return vc.visitAll(expr.Args...)
+}
+
+func (vc *variableCollector) VisitRef(expr *Ref) interface{} {
+	return vc.visitAll(expr.Addr)
+}
+
+func (vc *variableCollector) VisitFieldAccess(expr *FieldAccess) interface{} {
+	return vc.visitAll(expr.Heap, expr.Addr)
+}
+
+func (vc *variableCollector) VisitFieldAssign(expr *FieldAssign) interface{} {
+	return vc.visitAll(expr.Heap, expr.Addr, expr.Index, expr.Value)
+}
+
+func (vc *variableCollector) VisitArrayConstant(expr *ArrayConstant) interface{} {
+	return vc.visitAll(expr.Value)
+}
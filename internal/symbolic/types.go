@@ -19,6 +19,14 @@ type InnerType struct {
 	InnerTy *InnerType
 }
 
+// String возвращает строковое представление типа с учётом типа элементов массива
+func (it InnerType) String() string {
+	if it.ExprTy == ArrayType && it.InnerTy != nil {
+		return "[]" + it.InnerTy.String()
+	}
+	return it.ExprTy.String()
+}
+
 // String возвращает строковое представление типа
 func (et ExpressionType) String() string {
 	switch et {
@@ -30,6 +38,8 @@ func (et ExpressionType) String() string {
 		return "array"
 	case FunctionType:
 		return "function"
+	case ObjectType:
+		return "object"
 	case RefType:
 		return "reference"
 	default:
@@ -14,4 +14,5 @@ type Visitor interface {
 	VisitRef(expr *Ref) interface{}
 	VisitFieldAccess(expr *FieldAccess) interface{}
 	VisitFieldAssign(expr *FieldAssign) interface{}
+	VisitArrayConstant(expr *ArrayConstant) interface{}
 }
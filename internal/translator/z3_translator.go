@@ -3,7 +3,6 @@ package translator
 
 import (
 	"math/big"
-	"strconv"
 	"symbolic-execution-course/internal/memory"
 	"symbolic-execution-course/internal/symbolic"
 
@@ -12,26 +11,50 @@ import (
 
 // Z3Translator транслирует символьные выражения в Z3 формулы
 type Z3Translator struct {
-	ctx       *z3.Context
-	config    *z3.Config
-	vars      map[string]z3.Value // Кэш переменных
-	objArrays map[string]z3.Array
-	mem       memory.Memory
+	ctx    *z3.Context
+	config *z3.Config
+	vars   map[string]z3.Value // Кэш переменных
+	mem    memory.Memory
+
+	// Кэш трансляций по указателю на выражение: общие подвыражения
+	// (например, массив кучи в цепочке записей) транслируются один раз
+	cache  map[symbolic.SymbolicExpression]interface{}
+	derefs int // число разыменований ссылок на примитивы, см. translate
 }
 
-// NewZ3Translator создаёт новый экземпляр Z3 транслятора
-func NewZ3Translator() *Z3Translator {
+// NewZ3Translator создаёт новый экземпляр Z3 транслятора.
+// Память mem используется для разыменования ссылок и не изменяется трансляцией
+// (может быть nil: тогда ссылки читают исходное содержимое кучи)
+func NewZ3Translator(mem memory.Memory) *Z3Translator {
 	config := &z3.Config{}
 	ctx := z3.NewContext(config)
 
 	return &Z3Translator{
-		ctx:       ctx,
-		config:    config,
-		vars:      make(map[string]z3.Value),
-		objArrays: make(map[string]z3.Array),
+		ctx:    ctx,
+		config: config,
+		vars:   make(map[string]z3.Value),
+		mem:    mem,
+		cache:  make(map[symbolic.SymbolicExpression]interface{}),
 	}
 }
 
+// NewZ3TranslatorWithContext создаёт транслятор поверх уже существующего Z3 контекста,
+// чтобы транслированные выражения можно было передавать в solver этого контекста
+func NewZ3TranslatorWithContext(ctx *z3.Context, mem memory.Memory) *Z3Translator {
+	return &Z3Translator{
+		ctx:    ctx,
+		config: ctx.Config(),
+		vars:   make(map[string]z3.Value),
+		mem:    mem,
+		cache:  make(map[symbolic.SymbolicExpression]interface{}),
+	}
+}
+
+// Memory возвращает память, используемую для разыменования ссылок
+func (zt *Z3Translator) Memory() memory.Memory {
+	return zt.mem
+}
+
 // GetContext возвращает Z3 контекст
 func (zt *Z3Translator) GetContext() *z3.Context {
 	return zt.ctx
@@ -40,6 +63,7 @@ func (zt *Z3Translator) GetContext() *z3.Context {
 // Reset сбрасывает состояние транслятора
 func (zt *Z3Translator) Reset() {
 	zt.vars = make(map[string]z3.Value)
+	zt.cache = make(map[symbolic.SymbolicExpression]interface{})
 }
 
 // Close освобождает ресурсы
@@ -49,7 +73,22 @@ func (zt *Z3Translator) Close() {
 
 // TranslateExpression транслирует символьное выражение в Z3
 func (zt *Z3Translator) TranslateExpression(expr symbolic.SymbolicExpression) (interface{}, error) {
-	return expr.Accept(zt), nil
+	return zt.translate(expr), nil
+}
+
+// translate транслирует подвыражение, кэшируя результат по указателю. Выражения,
+// в которых разыменовывается ссылка на примитив, не кэшируются: их значение
+// зависит от текущего состояния памяти
+func (zt *Z3Translator) translate(expr symbolic.SymbolicExpression) interface{} {
+	if v, ok := zt.cache[expr]; ok {
+		return v
+	}
+	derefs := zt.derefs
+	v := expr.Accept(zt)
+	if zt.derefs == derefs {
+		zt.cache[expr] = v
+	}
+	return v
 }
 
 // VisitVariable транслирует символьную переменную в Z3
@@ -98,14 +137,6 @@ func (zt *Z3Translator) VisitBoolConstant(expr *symbolic.BoolConstant) interface
 	return zt.ctx.FromBool(expr.Value)
 }
 
-func Field2Key(name string, index int) string {
-	return "index_" + name + "." + strconv.Itoa(index)
-}
-
-func Field2Key2(name string, index string) string {
-	return name + "." + index
-}
-
 // VisitBinaryOperation транслирует бинарную операцию в Z3
 func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) interface{} {
 	// 1. Транслировать левый и правый операнды
@@ -115,8 +146,17 @@ func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) int
 	// - Сравнения: left.Eq(right), left.LT(right), left.LE(right), etc.
 	// - Приводите типы: left.(z3.Int), right.(z3.Int) для int операций
 
-	leftOp := expr.Left.Accept(zt)
-	rightOp := expr.Right.Accept(zt)
+	if expr.Left.Type() == symbolic.RefType && (expr.Operator == symbolic.EQ || expr.Operator == symbolic.NE) {
+		// Указатели равны, если равны их адреса: ссылки на примитивы не разыменовываются
+		left, right := zt.address(expr.Left), zt.address(expr.Right)
+		if expr.Operator == symbolic.EQ {
+			return left.Eq(right)
+		}
+		return left.NE(right)
+	}
+
+	leftOp := zt.translate(expr.Left)
+	rightOp := zt.translate(expr.Right)
 
 	switch expr.Operator {
 	// Arithmetic binary operations
@@ -246,21 +286,15 @@ func (zt *Z3Translator) VisitBinaryOperation(expr *symbolic.BinaryOperation) int
 		default:
 			panic("unknown type in VisitBinaryOperation")
 		}
-
-	case symbolic.FIELD_ACCESS:
+	case symbolic.STORE:
 		switch expr.Left.Type() {
-		case symbolic.ObjectType:
-			// TODO: panic if RHS isn't IntConstant
-			str := Field2Key2(expr.Left.String(), expr.Right.String())
-			_, ok := zt.objArrays[str]
-			if !ok {
-				zt.objArrays[str] = z3.Array{}
-			}
-
-			return zt.objArrays[str].Select(rightOp.(z3.Value))
+		case symbolic.ArrayType:
+			valueOp := zt.translate(expr.Value)
+			return leftOp.(z3.Array).Store(rightOp.(z3.Value), valueOp.(z3.Value))
 		default:
 			panic("unknown type in VisitBinaryOperation")
 		}
+
 	case symbolic.FIELD_ASSIGN:
 		// Treating objects fields as arrays
 		// In correspondance to allocated ones
@@ -291,7 +325,7 @@ func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) i
 	// - IMPLIES: antecedent.Implies(consequent)
 	var translatedOperands []z3.Value
 	for _, op := range expr.Operands {
-		z := op.Accept(zt)
+		z := zt.translate(op)
 		translatedOperands = append(translatedOperands, z.(z3.Value))
 	}
 
@@ -319,9 +353,9 @@ func (zt *Z3Translator) VisitLogicalOperation(expr *symbolic.LogicalOperation) i
 }
 
 func (zt *Z3Translator) VisitTernaryOperation(expr *symbolic.TernaryOperation) interface{} {
-	translatedOp := expr.Condition.Accept(zt)
-	trueOp := expr.TrueExpr.Accept(zt)
-	falseOp := expr.FalseExpr.Accept(zt)
+	translatedOp := zt.translate(expr.Condition)
+	trueOp := zt.translate(expr.TrueExpr)
+	falseOp := zt.translate(expr.FalseExpr)
 
 	return translatedOp.(z3.Bool).IfThenElse(trueOp.(z3.Value), falseOp.(z3.Value))
 }
@@ -329,10 +363,10 @@ func (zt *Z3Translator) VisitTernaryOperation(expr *symbolic.TernaryOperation) i
 func (zt *Z3Translator) VisitUnaryOperation(expr *symbolic.UnaryOperation) interface{} {
 	switch expr.Operator {
 	case symbolic.UN_NOT:
-		translatedExpr := expr.Expr.Accept(zt)
+		translatedExpr := zt.translate(expr.Expr)
 		return translatedExpr.(z3.Bool).Not()
 	case symbolic.UN_SUB:
-		translatedExpr := expr.Expr.Accept(zt)
+		translatedExpr := zt.translate(expr.Expr)
 		return translatedExpr.(z3.Int).Neg()
 	default:
 		panic("unknown unary operator")
@@ -353,7 +387,7 @@ func (zt *Z3Translator) VisitFunctionCall(expr *symbolic.FunctionCall) interface
 	var args []z3.Value
 	for i := range expr.Args {
 		arg := expr.Args[i]
-		translatedArg := arg.Accept(zt)
+		translatedArg := zt.translate(arg)
 		args = append(args, translatedArg.(z3.Value))
 	}
 	return decl.(z3.FuncDecl).Apply(args...)
@@ -362,44 +396,51 @@ func (zt *Z3Translator) VisitFunctionCall(expr *symbolic.FunctionCall) interface
 func (zt *Z3Translator) VisitRef(expr *symbolic.Ref) interface{} {
 	switch expr.MemTy {
 	case symbolic.Primitive:
-		return zt.mem.GetPrimitive(expr).(z3.Value)
-	case symbolic.Object:
-		return expr.ObjectAddr
+		mem := zt.mem
+		if mem == nil {
+			// Без памяти ссылка читает исходное содержимое кучи
+			mem = memory.NewSymbolicMemory()
+		}
+		zt.derefs++
+		return zt.translate(mem.PeekPrimitive(expr))
 	default:
-		return expr.ArrayAddr
+		// Ссылка на объект или массив транслируется в свой адрес
+		return zt.translate(expr.Addr)
 	}
 }
 
-func (zt *Z3Translator) VisitFieldAccess(expr *symbolic.FieldAccess) interface{} {
-	str := Field2Key(expr.Key.String(), expr.FieldIdx)
-	index := zt.ctx.Const(str, zt.ctx.IntSort())
-
-	fieldName := Field2Key(expr.StructName, expr.FieldIdx) // Not the same as "str"! We use it internally to locate the particular array for current field
-	_, ok := zt.objArrays[fieldName]
-	if !ok {
-		as := zt.ctx.ArraySort(zt.ctx.IntSort(), symbolic.Type2Sort(zt.ctx, &expr.InnerTy))
-		z := zt.ctx.Const(expr.Obj.String(), as)
-		zt.objArrays[fieldName] = z.(z3.Array)
+// address транслирует адрес указателя: ссылка на примитив при трансляции
+// разыменовывается, поэтому для сравнения указателей берётся её адрес
+func (zt *Z3Translator) address(expr symbolic.SymbolicExpression) z3.Int {
+	if ref, ok := expr.(*symbolic.Ref); ok {
+		return zt.translate(ref.Addr).(z3.Int)
 	}
+	return zt.translate(expr).(z3.Int)
+}
 
-	return zt.objArrays[fieldName].Select(index)
+// VisitFieldAccess транслирует чтение поля в select из массива поля
+func (zt *Z3Translator) VisitFieldAccess(expr *symbolic.FieldAccess) interface{} {
+	heap := zt.translate(expr.Heap)
+	addr := zt.translate(expr.Addr)
+	return heap.(z3.Array).Select(addr.(z3.Value))
 }
 
+// VisitFieldAssign транслирует запись поля в store в массив поля
 func (zt *Z3Translator) VisitFieldAssign(expr *symbolic.FieldAssign) interface{} {
-	str := Field2Key(expr.Obj.String(), expr.FieldIdx)
-	index := zt.ctx.Const(str, zt.ctx.IntSort())
-
-	fieldName := Field2Key(expr.StructName, expr.FieldIdx) // Not the same as "str"! We use it internally to locate the particular array for current field
-	_, ok := zt.objArrays[fieldName]
-	if !ok {
-		as := zt.ctx.ArraySort(zt.ctx.IntSort(), symbolic.Type2Sort2(zt.ctx, expr.Value))
-		z := zt.ctx.Const(expr.Obj.String(), as)
-		zt.objArrays[fieldName] = z.(z3.Array)
+	heap := zt.translate(expr.Heap).(z3.Array)
+	addr := zt.translate(expr.Addr).(z3.Value)
+	val := zt.translate(expr.Value).(z3.Value)
+	if expr.Index != nil {
+		index := zt.translate(expr.Index).(z3.Value)
+		val = heap.Select(addr).(z3.Array).Store(index, val)
 	}
+	return heap.Store(addr, val)
+}
 
-	val := expr.Value.Accept(zt)
-	zt.objArrays[fieldName] = zt.objArrays[fieldName].Store(index, val.(z3.Value))
-	return zt.objArrays[fieldName]
+// VisitArrayConstant транслирует массив из одинаковых элементов в константный массив Z3
+func (zt *Z3Translator) VisitArrayConstant(expr *symbolic.ArrayConstant) interface{} {
+	val := zt.translate(expr.Value)
+	return zt.ctx.ConstArray(zt.ctx.IntSort(), val.(z3.Value))
 }
 
 // Вспомогательные методы
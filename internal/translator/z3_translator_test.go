@@ -0,0 +1,226 @@
+package translator
+
+import (
+	"testing"
+	"time"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func TestVisitRefDereferencesPrimitive(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	ref := mem.AllocatePrimitive(symbolic.IntType)
+	mem.AssignPrimitive(ref, symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(1), symbolic.ADD))
+
+	z, err := zt.TranslateExpression(ref)
+	if err != nil {
+		t.Fatalf("Error translating reference: %v", err)
+	}
+	if _, ok := z.(z3.Int); !ok {
+		t.Fatalf("Expected dereferenced int value, got %T", z)
+	}
+
+	// Разные примитивы не должны делить одну ячейку
+	other := mem.AllocatePrimitive(symbolic.IntType)
+	mem.AssignPrimitive(other, symbolic.NewIntConstant(7))
+	if mem.GetPrimitive(ref).String() == mem.GetPrimitive(other).String() {
+		t.Errorf("Expected distinct cells for distinct primitives")
+	}
+}
+
+func TestVisitRefDoesNotChangeMemory(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	ref := mem.AllocatePrimitive(symbolic.IntType)
+
+	if _, err := NewZ3Translator(mem).TranslateExpression(ref); err != nil {
+		t.Fatalf("Error translating reference: %v", err)
+	}
+	if len(mem.Heap) != 0 {
+		t.Errorf("Expected translation to leave the heap empty, got %v", mem.Heap)
+	}
+	if _, err := NewZ3Translator(nil).TranslateExpression(ref); err != nil {
+		t.Errorf("Error translating reference without memory: %v", err)
+	}
+}
+
+func TestPointerComparisonDoesNotReadMemory(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	p := mem.AllocatePrimitive(symbolic.IntType)
+	q := mem.AllocatePrimitive(symbolic.IntType)
+	eq := symbolic.NewBinaryOperation(p, q, symbolic.EQ)
+	ne := symbolic.NewBinaryOperation(p, p, symbolic.NE)
+
+	for _, zt := range []*Z3Translator{NewZ3Translator(mem), NewZ3Translator(nil)} {
+		s := z3.NewSolver(zt.GetContext())
+		for _, cond := range []symbolic.SymbolicExpression{eq, ne} {
+			z, err := zt.TranslateExpression(cond)
+			if err != nil {
+				t.Fatalf("Error translating %v: %v", cond, err)
+			}
+			s.Push()
+			s.Assert(z.(z3.Bool))
+			if sat, err := s.Check(); err != nil || sat {
+				t.Errorf("Expected %v to be unsatisfiable, got %v (%v)", cond, sat, err)
+			}
+			s.Pop()
+		}
+	}
+	if len(mem.Heap) != 0 {
+		t.Errorf("Expected pointer comparison to leave the heap empty, got %v", mem.Heap)
+	}
+}
+
+func TestHeapAliasingDecidedBySolver(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// foo2.a = 5; foo1.a = 2; fresh := &Foo{a: 7}
+	foo1 := mem.InputRef("foo1", symbolic.Object, "Foo")
+	foo2 := mem.InputRef("foo2", symbolic.Object, "Foo")
+	fresh := mem.AllocateStruct("Foo")
+	mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
+	mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
+	mem.AssignField(fresh, 0, symbolic.NewIntConstant(7))
+
+	check := func(conds ...symbolic.SymbolicExpression) bool {
+		s := z3.NewSolver(zt.GetContext())
+		for _, c := range append(mem.Constraints(), conds...) {
+			z, _ := zt.TranslateExpression(c)
+			s.Assert(z.(z3.Bool))
+		}
+		sat, err := s.Check()
+		if err != nil {
+			t.Fatalf("Unexpected solver error: %v", err)
+		}
+		return sat
+	}
+
+	foo2a := mem.GetFieldValue(foo2, 0, intTy)
+	sameAddr := symbolic.NewBinaryOperation(foo1.Addr, foo2.Addr, symbolic.EQ)
+	if !check(symbolic.NewBinaryOperation(foo2a, symbolic.NewIntConstant(2), symbolic.EQ)) {
+		t.Error("Expected foo2.a == 2 to be feasible when foo1 and foo2 alias")
+	}
+	if check(symbolic.NewBinaryOperation(foo2a, symbolic.NewIntConstant(2), symbolic.EQ), symbolic.NewUnaryOperation(symbolic.UN_NOT, sameAddr)) {
+		t.Error("Expected foo2.a == 2 to be infeasible without aliasing")
+	}
+	// Новый объект не может совпадать с входным
+	if check(symbolic.NewBinaryOperation(mem.GetFieldValue(foo1, 0, intTy), symbolic.NewIntConstant(7), symbolic.EQ)) {
+		t.Error("Expected freshly allocated object not to alias an input pointer")
+	}
+}
+
+func TestSymbolicArrayIndex(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// arr[i] = 10; return arr[j]
+	arr := mem.InputRef("arr", symbolic.Array, "[]int")
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	j := symbolic.NewSymbolicVariable("j", symbolic.IntType)
+	mem.AssignToArray(arr, i, symbolic.NewIntConstant(10))
+	read := mem.GetFromArray(arr, j, intTy)
+
+	s := z3.NewSolver(zt.GetContext())
+	for _, c := range []symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(i, j, symbolic.EQ),
+		symbolic.NewBinaryOperation(read, symbolic.NewIntConstant(10), symbolic.NE),
+	} {
+		z, _ := zt.TranslateExpression(c)
+		s.Assert(z.(z3.Bool))
+	}
+	if sat, _ := s.Check(); sat {
+		t.Error("Expected arr[j] == 10 when i == j")
+	}
+}
+
+func TestPointerComparison(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+	check := func(conds ...symbolic.SymbolicExpression) bool {
+		s := z3.NewSolver(zt.GetContext())
+		for _, c := range append(mem.Constraints(), conds...) {
+			z, _ := zt.TranslateExpression(c)
+			s.Assert(z.(z3.Bool))
+		}
+		sat, _ := s.Check()
+		return sat
+	}
+
+	// func testStructPointerModification(p *Person) { if p != nil { ... } }
+	p := mem.InputPointer("p", "Person")
+	nilRef := symbolic.NewNilRef("Person")
+	if !check(symbolic.NewBinaryOperation(p, nilRef, symbolic.EQ)) || !check(symbolic.NewBinaryOperation(p, nilRef, symbolic.NE)) {
+		t.Error("Expected both p == nil and p != nil to be feasible")
+	}
+	fresh := mem.AllocateStruct("Person")
+	if check(symbolic.NewIsNil(fresh)) || check(symbolic.NewBinaryOperation(p, fresh, symbolic.EQ)) {
+		t.Error("Expected new object to be non-nil and distinct from inputs")
+	}
+
+	// Указатели на примитивы сравниваются по адресам, а не по значениям
+	a := mem.AllocatePrimitive(symbolic.IntType)
+	b := mem.AllocatePrimitive(symbolic.IntType)
+	mem.AssignPrimitive(a, symbolic.NewIntConstant(1))
+	mem.AssignPrimitive(b, symbolic.NewIntConstant(1))
+	if check(symbolic.NewBinaryOperation(a, b, symbolic.EQ)) || !check(symbolic.NewBinaryOperation(a, a, symbolic.EQ)) {
+		t.Error("Expected &a != &b and &a == &a")
+	}
+}
+
+func TestSharedSubexpressionsTranslatedOnce(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// for i := 0; i < 50; i++ { p.x = p.x + 1 }: каждое чтение ссылается на
+	// предыдущий массив кучи, без кэша размер формулы растёт экспоненциально
+	p := mem.InputRef("p", symbolic.Object, "P")
+	x0 := mem.GetFieldValue(p, 0, intTy)
+	for i := 0; i < 50; i++ {
+		mem.AssignField(p, 0, symbolic.NewBinaryOperation(mem.GetFieldValue(p, 0, intTy), symbolic.NewIntConstant(1), symbolic.ADD))
+	}
+
+	done := make(chan bool)
+	go func() {
+		s := z3.NewSolver(zt.GetContext())
+		diff := symbolic.NewBinaryOperation(symbolic.NewBinaryOperation(mem.GetFieldValue(p, 0, intTy), x0, symbolic.SUB), symbolic.NewIntConstant(50), symbolic.NE)
+		z, _ := zt.TranslateExpression(diff)
+		s.Assert(z.(z3.Bool))
+		sat, _ := s.Check()
+		done <- sat
+	}()
+	select {
+	case sat := <-done:
+		if sat {
+			t.Error("Expected p.x to grow by exactly 50")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Translation of 50 field updates took too long")
+	}
+}
+
+func TestCachedTranslationSeesPrimitiveUpdates(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	zt := NewZ3Translator(mem)
+
+	ref := mem.AllocatePrimitive(symbolic.IntType)
+	mem.AssignPrimitive(ref, symbolic.NewIntConstant(1))
+	zt.TranslateExpression(ref)
+
+	// Разыменование транслируется заново по текущей памяти
+	mem.AssignPrimitive(ref, symbolic.NewIntConstant(2))
+	z, _ := zt.TranslateExpression(ref)
+	s := z3.NewSolver(zt.GetContext())
+	s.Assert(z.(z3.Int).Eq(zt.GetContext().FromInt(1, zt.GetContext().IntSort()).(z3.Int)))
+	if sat, _ := s.Check(); sat {
+		t.Error("Expected translation to use the updated value of the primitive")
+	}
+}
@@ -0,0 +1,274 @@
+package z3wrapper
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// CacheStats - статистика попаданий в кэш запросов
+type CacheStats struct {
+	Queries int // всего вызовов Check
+	Hits    int // ответ найден в кэше для того же множества ограничений
+	// Ответ выведен из модели надмножества ограничений
+	SupersetHits int
+	// Ответ выведен из невыполнимого подмножества ограничений
+	SubsetHits int
+	Misses     int // запрос передан в Z3
+}
+
+// cacheEntry - закэшированный ответ для множества ограничений
+type cacheEntry struct {
+	Constraints []string `json:"constraints"`
+	Sat         bool     `json:"sat"`
+	// Модель доступна только для ответов, полученных в текущем запуске
+	model *z3.Model
+}
+
+// CachingSolver - обёртка над Solver, кэширующая ответы на запросы.
+//
+// Ключ кэша - каноническое множество ограничений, включая ограничения самого
+// обёрнутого solver'а: упрощённые Z3 выражения, отсортированные по строковому
+// представлению, без повторов и тривиально истинных ограничений. Помимо точных попаданий используется переиспользование
+// контрпримеров в стиле KLEE: модель надмножества ограничений является моделью
+// и для подмножества, а невыполнимое подмножество делает невыполнимым и надмножество
+type CachingSolver struct {
+	solver *Solver
+
+	assertions []z3.Bool
+	scopes     []int
+
+	entries map[string]*cacheEntry
+	sat     []*cacheEntry
+	unsat   []*cacheEntry
+	stats   CacheStats
+
+	lastSat   bool
+	lastModel *z3.Model
+}
+
+// NewCachingSolver создаёт кэширующую обёртку над solver
+func NewCachingSolver(solver *Solver) *CachingSolver {
+	return &CachingSolver{
+		solver:  solver,
+		entries: make(map[string]*cacheEntry),
+	}
+}
+
+// Solver возвращает обёрнутый solver
+func (cs *CachingSolver) Solver() *Solver {
+	return cs.solver
+}
+
+// Context возвращает контекст Z3
+func (cs *CachingSolver) Context() *z3.Context {
+	return cs.solver.Context()
+}
+
+// Assert добавляет ограничение
+func (cs *CachingSolver) Assert(constraint z3.Bool) {
+	cs.assertions = append(cs.assertions, constraint)
+}
+
+// Push сохраняет текущий набор ограничений
+func (cs *CachingSolver) Push() {
+	cs.scopes = append(cs.scopes, len(cs.assertions))
+}
+
+// Pop восстанавливает набор ограничений, сохранённый последним Push
+func (cs *CachingSolver) Pop() {
+	n := len(cs.scopes) - 1
+	cs.assertions = cs.assertions[:cs.scopes[n]]
+	cs.scopes = cs.scopes[:n]
+}
+
+// Stats возвращает статистику кэша
+func (cs *CachingSolver) Stats() CacheStats {
+	return cs.stats
+}
+
+// Check проверяет выполнимость текущих ограничений, по возможности без обращения к Z3
+func (cs *CachingSolver) Check() (bool, error) {
+	cs.stats.Queries++
+	cs.lastModel = nil
+
+	constraints, trivial := cs.canonicalize()
+	if trivial {
+		cs.stats.Hits++
+		cs.lastSat = false
+		return false, nil
+	}
+	key := strings.Join(constraints, "\n")
+
+	if entry, ok := cs.entries[key]; ok {
+		cs.stats.Hits++
+		cs.lastSat, cs.lastModel = entry.Sat, entry.model
+		return entry.Sat, nil
+	}
+	if entry := cs.findUnsatSubset(constraints); entry != nil {
+		cs.stats.SubsetHits++
+		cs.remember(key, constraints, false, nil)
+		cs.lastSat = false
+		return false, nil
+	}
+	if entry := cs.findSatSuperset(constraints); entry != nil {
+		cs.stats.SupersetHits++
+		cs.remember(key, constraints, true, entry.model)
+		cs.lastSat, cs.lastModel = true, entry.model
+		return true, nil
+	}
+
+	cs.stats.Misses++
+	sat, model, err := cs.solve()
+	if err != nil {
+		return false, err
+	}
+	cs.remember(key, constraints, sat, model)
+	cs.lastSat, cs.lastModel = sat, model
+	return sat, nil
+}
+
+// Model возвращает модель для последнего выполнимого Check.
+// Если ответ был загружен с диска, модель строится повторным запросом к Z3
+func (cs *CachingSolver) Model() *z3.Model {
+	if !cs.lastSat {
+		return nil
+	}
+	if cs.lastModel == nil {
+		if _, model, err := cs.solve(); err == nil {
+			cs.lastModel = model
+		}
+	}
+	return cs.lastModel
+}
+
+// solve передаёт текущие ограничения в Z3
+func (cs *CachingSolver) solve() (bool, *z3.Model, error) {
+	cs.solver.Push()
+	defer cs.solver.Pop()
+	for _, a := range cs.assertions {
+		cs.solver.Assert(a)
+	}
+	sat, err := cs.solver.Check()
+	if err != nil || !sat {
+		return false, nil, err
+	}
+	return true, cs.solver.Model(), nil
+}
+
+// canonicalize строит каноническое множество ограничений обёртки и обёрнутого
+// solver'а: solve проверяет их вместе. Второй результат true, если одно
+// из ограничений тождественно ложно
+func (cs *CachingSolver) canonicalize() ([]string, bool) {
+	seen := make(map[string]bool)
+	var res []string
+	for _, a := range append(cs.solver.Assertions(), cs.assertions...) {
+		str := cs.Context().Simplify(a, nil).String()
+		switch {
+		case str == "true" || seen[str]:
+			continue
+		case str == "false":
+			return nil, true
+		}
+		seen[str] = true
+		res = append(res, str)
+	}
+	sort.Strings(res)
+	return res, false
+}
+
+func (cs *CachingSolver) remember(key string, constraints []string, sat bool, model *z3.Model) {
+	entry := &cacheEntry{Constraints: constraints, Sat: sat, model: model}
+	cs.entries[key] = entry
+	if sat {
+		if model != nil {
+			cs.sat = append(cs.sat, entry)
+		}
+	} else {
+		cs.unsat = append(cs.unsat, entry)
+	}
+}
+
+// findUnsatSubset ищет невыполнимое подмножество constraints
+func (cs *CachingSolver) findUnsatSubset(constraints []string) *cacheEntry {
+	set := toSet(constraints)
+	for _, entry := range cs.unsat {
+		if isSubset(entry.Constraints, set) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// findSatSuperset ищет выполнимое надмножество constraints с известной моделью
+func (cs *CachingSolver) findSatSuperset(constraints []string) *cacheEntry {
+	for _, entry := range cs.sat {
+		if isSubset(constraints, toSet(entry.Constraints)) {
+			return entry
+		}
+	}
+	return nil
+}
+
+func toSet(items []string) map[string]bool {
+	set := make(map[string]bool, len(items))
+	for _, item := range items {
+		set[item] = true
+	}
+	return set
+}
+
+func isSubset(items []string, set map[string]bool) bool {
+	if len(items) > len(set) {
+		return false
+	}
+	for _, item := range items {
+		if !set[item] {
+			return false
+		}
+	}
+	return true
+}
+
+// SaveCache сохраняет закэшированные ответы в файл (модели не сохраняются)
+func (cs *CachingSolver) SaveCache(path string) error {
+	entries := make([]*cacheEntry, 0, len(cs.entries))
+	for _, entry := range cs.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return strings.Join(entries[i].Constraints, "\n") < strings.Join(entries[j].Constraints, "\n")
+	})
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode query cache: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write query cache: %w", err)
+	}
+	return nil
+}
+
+// LoadCache загружает ответы, сохранённые SaveCache в предыдущих запусках
+func (cs *CachingSolver) LoadCache(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read query cache: %w", err)
+	}
+	var entries []*cacheEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to decode query cache: %w", err)
+	}
+	for _, entry := range entries {
+		key := strings.Join(entry.Constraints, "\n")
+		if _, ok := cs.entries[key]; !ok {
+			cs.remember(key, entry.Constraints, entry.Sat, nil)
+		}
+	}
+	return nil
+}
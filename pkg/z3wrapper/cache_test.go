@@ -0,0 +1,123 @@
+package z3wrapper
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCachingSolverReuse(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	cs := NewCachingSolver(solver)
+
+	x := solver.CreateIntVar("x")
+	y := solver.CreateIntVar("y")
+	zero := solver.CreateIntLit(0)
+	ten := solver.CreateIntLit(10)
+
+	// x > 0, y > x: выполнимо, запрос уходит в Z3
+	cs.Assert(x.GT(zero))
+	cs.Push()
+	cs.Assert(y.GT(x))
+	if sat, err := cs.Check(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+	cs.Pop()
+
+	// x > 0 - подмножество, ответ берётся из модели надмножества
+	if sat, _ := cs.Check(); !sat {
+		t.Fatal("Expected x > 0 to be satisfiable")
+	}
+	if xVal, err := solver.GetIntValue(cs.Model(), x); err != nil || xVal <= 0 {
+		t.Errorf("Expected reused model with x > 0, got %d, %v", xVal, err)
+	}
+
+	// x > 0, x < 0 - невыполнимо
+	cs.Push()
+	cs.Assert(x.LT(zero))
+	if sat, _ := cs.Check(); sat {
+		t.Fatal("Expected x > 0 AND x < 0 to be unsatisfiable")
+	}
+	// Надмножество невыполнимого множества невыполнимо без запроса к Z3
+	cs.Assert(y.Eq(ten))
+	if sat, _ := cs.Check(); sat {
+		t.Fatal("Expected superset of unsatisfiable set to be unsatisfiable")
+	}
+	cs.Pop()
+
+	// Повторный запрос того же множества
+	if sat, _ := cs.Check(); !sat {
+		t.Fatal("Expected x > 0 to be satisfiable")
+	}
+
+	stats := cs.Stats()
+	if stats.Queries != 5 || stats.Misses != 2 || stats.SupersetHits != 1 || stats.SubsetHits != 1 || stats.Hits != 1 {
+		t.Errorf("Unexpected cache statistics: %+v", stats)
+	}
+}
+
+func TestCachingSolverPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	solver := NewSolver()
+	cs := NewCachingSolver(solver)
+	x := solver.CreateIntVar("x")
+	cs.Assert(x.Eq(solver.CreateIntLit(5)))
+	cs.Check()
+	if err := cs.SaveCache(path); err != nil {
+		t.Fatalf("Error saving cache: %v", err)
+	}
+
+	// Новый запуск: другой контекст, те же ограничения
+	solver2 := NewSolver()
+	cs2 := NewCachingSolver(solver2)
+	if err := cs2.LoadCache(path); err != nil {
+		t.Fatalf("Error loading cache: %v", err)
+	}
+	x2 := solver2.CreateIntVar("x")
+	cs2.Assert(x2.Eq(solver2.CreateIntLit(5)))
+	if sat, _ := cs2.Check(); !sat {
+		t.Fatal("Expected x = 5 to be satisfiable")
+	}
+	if stats := cs2.Stats(); stats.Hits != 1 || stats.Misses != 0 {
+		t.Errorf("Expected answer from persisted cache, got %+v", stats)
+	}
+	// Модель строится по требованию
+	if xVal, err := solver2.GetIntValue(cs2.Model(), x2); err != nil || xVal != 5 {
+		t.Errorf("Expected x = 5, got %d, %v", xVal, err)
+	}
+}
+
+func TestCachingSolverKeyIncludesBaseAssertions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.json")
+	solver := NewSolver()
+	defer solver.Close()
+	cs := NewCachingSolver(solver)
+	x := solver.CreateIntVar("x")
+
+	// x < 5 выполнимо, пока у обёрнутого solver'а нет своих ограничений
+	cs.Assert(x.LT(solver.CreateIntLit(5)))
+	if sat, err := cs.Check(); err != nil || !sat {
+		t.Fatalf("Expected x < 5 to be satisfiable, got %v, %v", sat, err)
+	}
+	if err := cs.SaveCache(path); err != nil {
+		t.Fatal(err)
+	}
+
+	solver.Assert(x.GT(solver.CreateIntLit(10)))
+	if sat, err := cs.Check(); err != nil || sat {
+		t.Errorf("Expected x > 10 of the base solver to make x < 5 unsatisfiable, got %v, %v", sat, err)
+	}
+
+	other := NewSolver()
+	defer other.Close()
+	other.Assert(other.CreateIntVar("x").GT(other.CreateIntLit(10)))
+	loaded := NewCachingSolver(other)
+	if err := loaded.LoadCache(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Assert(other.CreateIntVar("x").LT(other.CreateIntLit(5)))
+	if sat, err := loaded.Check(); err != nil || sat {
+		t.Errorf("Expected persisted answer not to apply to a base solver with assertions, got %v, %v", sat, err)
+	}
+}
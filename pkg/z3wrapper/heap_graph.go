@@ -0,0 +1,119 @@
+package z3wrapper
+
+import (
+	"fmt"
+	"math/big"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// ReadHeapGraph строит конкретную кучу по символьному графу g в модели: адреса
+// вычисляются, объекты с совпавшими адресами склеиваются, а значения полей
+// читаются из массивов кучи по адресу объекта. Адреса, индексы и значения
+// модели хранятся в узлах точно, текстом, и используются только для отображения
+func (r *ModelReader) ReadHeapGraph(model *z3.Model, g *memory.HeapGraph) (*memory.HeapGraph, error) {
+	res := &memory.HeapGraph{}
+	nodes := make(map[string]*memory.HeapNode)
+	seen := make(map[string]bool)
+	node := func(addr *big.Int, typeName string) *memory.HeapNode {
+		id := "obj" + addr.String()
+		if addr.Sign() < 0 {
+			id = "obj_" + new(big.Int).Neg(addr).String()
+		}
+		if n, ok := nodes[id]; ok {
+			if n.Type == "" {
+				n.Type = typeName
+			}
+			return n
+		}
+		n := &memory.HeapNode{ID: id, Type: typeName, AddrText: addr.String()}
+		nodes[id] = n
+		res.Nodes = append(res.Nodes, n)
+		return n
+	}
+
+	for _, n := range g.Nodes {
+		addr, err := r.readInt(model, n.Addr)
+		if err != nil {
+			return nil, err
+		}
+		if addr.Sign() == 0 {
+			continue
+		}
+		owner := node(addr, n.Type)
+		fields := append([]memory.HeapField(nil), n.Fields...)
+		for _, e := range g.Edges {
+			if e.From == n.ID {
+				fields = append(fields, e.Field)
+			}
+		}
+		for _, f := range fields {
+			key := owner.ID + "/" + f.Heap.String()
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			value, err := r.readField(model, f.Heap, n.Addr)
+			if err != nil {
+				return nil, err
+			}
+			if f.Value.Type() == symbolic.RefType {
+				target := value.(*big.Int)
+				if target.Sign() == 0 {
+					owner.Fields = append(owner.Fields, memory.HeapField{Name: f.Name, ValueText: "nil"})
+					continue
+				}
+				to := node(target, "")
+				res.Edges = append(res.Edges, memory.HeapEdge{From: owner.ID, To: to.ID, Field: memory.HeapField{Name: f.Name}})
+				continue
+			}
+			owner.Fields = append(owner.Fields, concreteFields(f.Name, value)...)
+		}
+	}
+	return res, nil
+}
+
+// readInt вычисляет целочисленное выражение в модели
+func (r *ModelReader) readInt(model *z3.Model, expr symbolic.SymbolicExpression) (*big.Int, error) {
+	z, err := r.translator.TranslateExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+	value, err := r.ReadValue(model, z.(z3.Value))
+	if err != nil {
+		return nil, err
+	}
+	res, ok := value.(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("expected integer value of %s, got %v", expr.String(), value)
+	}
+	return res, nil
+}
+
+// readField вычисляет значение массива кучи heap по адресу addr
+func (r *ModelReader) readField(model *z3.Model, heap, addr symbolic.SymbolicExpression) (interface{}, error) {
+	h, err := r.translator.TranslateExpression(heap)
+	if err != nil {
+		return nil, err
+	}
+	a, err := r.translator.TranslateExpression(addr)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadValue(model, h.(z3.Array).Select(a.(z3.Value)))
+}
+
+// concreteFields превращает значение модели в поля узла; массив раскладывается на ячейки
+func concreteFields(name string, value interface{}) []memory.HeapField {
+	if arr, ok := value.(*ArrayValue); ok {
+		var res []memory.HeapField
+		for _, e := range arr.Entries {
+			res = append(res, memory.HeapField{Name: name, IndexText: fmt.Sprint(e.Index), ValueText: fmt.Sprint(e.Value)})
+		}
+		return append(res, memory.HeapField{Name: name, IndexText: "*", ValueText: fmt.Sprint(arr.Default)})
+	}
+	return []memory.HeapField{{Name: name, ValueText: fmt.Sprint(value)}}
+}
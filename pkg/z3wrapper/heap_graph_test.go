@@ -0,0 +1,76 @@
+package z3wrapper
+
+import (
+	"strings"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func TestReadHeapGraphMergesAliases(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	tr := translator.NewZ3Translator(mem)
+	solver := NewSolverWithContext(tr.GetContext())
+
+	// foo2.a = 5; foo1.a = 2 при foo1 == foo2
+	foo1 := mem.InputRef("foo1", symbolic.Object, "Foo")
+	foo2 := mem.InputRef("foo2", symbolic.Object, "Foo")
+	mem.AssignField(foo2, 0, symbolic.NewIntConstant(5))
+	mem.AssignField(foo1, 0, symbolic.NewIntConstant(2))
+	for _, c := range append(mem.Constraints(), symbolic.NewBinaryOperation(foo1, foo2, symbolic.EQ)) {
+		z, _ := tr.TranslateExpression(c)
+		solver.Assert(z.(z3.Bool))
+	}
+	if sat, err := solver.IsSatisfiable(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+
+	g, err := NewModelReader(tr).ReadHeapGraph(solver.Model(), mem.HeapGraph())
+	if err != nil {
+		t.Fatalf("Error reading heap: %v", err)
+	}
+	if len(g.Nodes) != 1 {
+		t.Fatalf("Expected aliased inputs to be a single object, got %d", len(g.Nodes))
+	}
+	var sb strings.Builder
+	if err := g.WriteDOT(&sb); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(sb.String(), "0 = 2") || strings.Contains(sb.String(), "0 = 5") {
+		t.Errorf("Expected the later write to win in concrete heap:\n%s", sb.String())
+	}
+}
+
+func TestReadHeapGraphKeepsExactValues(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	tr := translator.NewZ3Translator(mem)
+	solver := NewSolverWithContext(tr.GetContext())
+
+	// foo.a = 2^65 + 1: значение не помещается в int64
+	foo := mem.InputRef("foo", symbolic.Object, "Foo")
+	huge := symbolic.NewBinaryOperation(symbolic.NewBinaryOperation(
+		symbolic.NewIntConstant(1<<62), symbolic.NewIntConstant(8), symbolic.MUL), symbolic.NewIntConstant(1), symbolic.ADD)
+	mem.AssignField(foo, 0, huge)
+	for _, c := range mem.Constraints() {
+		z, _ := tr.TranslateExpression(c)
+		solver.Assert(z.(z3.Bool))
+	}
+	if sat, err := solver.IsSatisfiable(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+
+	g, err := NewModelReader(tr).ReadHeapGraph(solver.Model(), mem.HeapGraph())
+	if err != nil {
+		t.Fatalf("Error reading heap: %v", err)
+	}
+	if len(g.Nodes) != 1 || len(g.Nodes[0].Fields) != 1 {
+		t.Fatalf("Expected a single object with one field, got %+v", g.Nodes)
+	}
+	if f := g.Nodes[0].Fields[0]; f.ValueText != "36893488147419103233" || f.Value != nil {
+		t.Errorf("Expected the exact model value as text, got %+v", f)
+	}
+}
@@ -0,0 +1,124 @@
+package z3wrapper
+
+import (
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// IndependentSolver проверяет выполнимость условий пути, разбивая их на независимые
+// по переменным группы (constraint independence). В Z3 отправляется только группа,
+// связанная с новым условием ветвления; ответы и модели групп кэшируются CachingSolver'ом
+type IndependentSolver struct {
+	translator  *translator.Z3Translator
+	cache       *CachingSolver
+	constraints []symbolic.SymbolicExpression
+	scopes      []int
+
+	// Модель группы, в которую последней проверкой попала переменная (по имени)
+	models map[string]*z3.Model
+}
+
+// NewIndependentSolver создаёт solver над контекстом транслятора
+func NewIndependentSolver(tr *translator.Z3Translator) *IndependentSolver {
+	return &IndependentSolver{
+		translator: tr,
+		cache:      NewCachingSolver(NewSolverWithContext(tr.GetContext())),
+		models:     make(map[string]*z3.Model),
+	}
+}
+
+// Assert добавляет ограничение в условие пути
+func (s *IndependentSolver) Assert(constraint symbolic.SymbolicExpression) {
+	s.constraints = append(s.constraints, constraint)
+}
+
+// Push сохраняет текущее условие пути
+func (s *IndependentSolver) Push() {
+	s.scopes = append(s.scopes, len(s.constraints))
+}
+
+// Pop восстанавливает условие пути, сохранённое последним Push
+func (s *IndependentSolver) Pop() {
+	n := len(s.scopes) - 1
+	s.constraints = s.constraints[:s.scopes[n]]
+	s.scopes = s.scopes[:n]
+}
+
+// Constraints возвращает текущее условие пути
+func (s *IndependentSolver) Constraints() []symbolic.SymbolicExpression {
+	return s.constraints
+}
+
+// CacheStats возвращает статистику кэша запросов
+func (s *IndependentSolver) CacheStats() CacheStats {
+	return s.cache.Stats()
+}
+
+// CheckBranch проверяет выполнимость условия пути вместе с условием ветвления cond.
+// Предполагается, что условие пути само по себе выполнимо, поэтому проверяется
+// только группа ограничений, связанная с cond. Ветвь лишь гипотетическая,
+// поэтому модели, которые возвращает Read, не меняются
+func (s *IndependentSolver) CheckBranch(cond symbolic.SymbolicExpression) (bool, error) {
+	slice := symbolic.SliceConstraints(s.constraints, cond)
+	return s.checkGroup(append(slice, cond), false)
+}
+
+// Check проверяет выполнимость всего условия пути, по группе за запрос
+func (s *IndependentSolver) Check() (bool, error) {
+	for _, group := range symbolic.PartitionConstraints(s.constraints) {
+		sat, err := s.checkGroup(group, true)
+		if err != nil || !sat {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// checkGroup проверяет группу ограничений; при record модель группы
+// запоминается для Read
+func (s *IndependentSolver) checkGroup(group []symbolic.SymbolicExpression, record bool) (bool, error) {
+	s.cache.Push()
+	defer s.cache.Pop()
+	for _, c := range group {
+		z, err := s.translator.TranslateExpression(c)
+		if err != nil {
+			return false, err
+		}
+		s.cache.Assert(z.(z3.Bool))
+	}
+
+	sat, err := s.cache.Check()
+	if err != nil || !sat || !record {
+		return sat, err
+	}
+	model := s.cache.Model()
+	for _, c := range group {
+		for _, v := range symbolic.FreeVariables(c) {
+			s.models[v.Name] = model
+		}
+	}
+	return true, nil
+}
+
+// Read извлекает значения переменных из моделей их групп, найденных последними проверками.
+// Переменные, не встречавшиеся в проверенных группах, не ограничены и читаются из пустой модели
+func (s *IndependentSolver) Read(vars []*symbolic.SymbolicVariable) (map[*symbolic.SymbolicVariable]interface{}, error) {
+	reader := NewModelReader(s.translator)
+	res := make(map[*symbolic.SymbolicVariable]interface{}, len(vars))
+	for _, v := range vars {
+		model, ok := s.models[v.Name]
+		if !ok {
+			empty := z3.NewSolver(s.translator.GetContext())
+			empty.Check()
+			model = empty.Model()
+		}
+		value, err := reader.ReadVariable(model, v)
+		if err != nil {
+			return nil, err
+		}
+		res[v] = value
+	}
+	return res, nil
+}
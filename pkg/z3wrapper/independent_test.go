@@ -0,0 +1,88 @@
+package z3wrapper
+
+import (
+	"math/big"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+func TestPartitionConstraints(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	z := symbolic.NewSymbolicVariable("z", symbolic.IntType)
+
+	xy := symbolic.NewBinaryOperation(x, y, symbolic.LT)
+	yPos := symbolic.NewBinaryOperation(y, intConst(0), symbolic.GT)
+	zNeg := symbolic.NewBinaryOperation(z, intConst(0), symbolic.LT)
+
+	groups := symbolic.PartitionConstraints([]symbolic.SymbolicExpression{xy, zNeg, yPos})
+	if len(groups) != 2 || len(groups[0]) != 2 || len(groups[1]) != 1 {
+		t.Fatalf("Expected groups {x < y, y > 0} and {z < 0}, got %v", groups)
+	}
+
+	slice := symbolic.SliceConstraints([]symbolic.SymbolicExpression{xy, zNeg, yPos}, symbolic.NewBinaryOperation(x, intConst(5), symbolic.EQ))
+	if len(slice) != 2 || slice[0] != xy || slice[1] != yPos {
+		t.Errorf("Expected slice {x < y, y > 0}, got %v", slice)
+	}
+}
+
+func TestIndependentSolver(t *testing.T) {
+	s := NewIndependentSolver(translator.NewZ3Translator(nil))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+	z := symbolic.NewSymbolicVariable("z", symbolic.IntType)
+
+	s.Assert(symbolic.NewBinaryOperation(x, y, symbolic.LT))
+	s.Assert(symbolic.NewBinaryOperation(z, intConst(-3), symbolic.EQ))
+	if sat, err := s.Check(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable path condition, got %v, %v", sat, err)
+	}
+
+	// Ветвление по z затрагивает только группу {z = -3}
+	if sat, _ := s.CheckBranch(symbolic.NewBinaryOperation(z, intConst(0), symbolic.GT)); sat {
+		t.Error("Expected z > 0 to be infeasible")
+	}
+	if sat, _ := s.CheckBranch(symbolic.NewBinaryOperation(x, intConst(100), symbolic.GT)); !sat {
+		t.Error("Expected x > 100 to be feasible")
+	}
+
+	// Повторная проверка всего пути отвечается из кэша
+	stats := s.CacheStats()
+	s.Check()
+	if after := s.CacheStats(); after.Misses != stats.Misses {
+		t.Errorf("Expected cached answers for unchanged groups, got %+v", after)
+	}
+
+	values, err := s.Read([]*symbolic.SymbolicVariable{x, y, z})
+	if err != nil {
+		t.Fatalf("Error reading model: %v", err)
+	}
+	if values[x].(*big.Int).Cmp(values[y].(*big.Int)) >= 0 || values[z].(*big.Int).Int64() != -3 {
+		t.Errorf("Model does not satisfy path condition: %v", values)
+	}
+}
+
+func TestReadAfterCheckBranch(t *testing.T) {
+	s := NewIndependentSolver(translator.NewZ3Translator(nil))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+
+	s.Assert(symbolic.NewBinaryOperation(x, intConst(0), symbolic.GT))
+	if sat, err := s.Check(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable path condition, got %v, %v", sat, err)
+	}
+	before, _ := s.Read([]*symbolic.SymbolicVariable{x})
+
+	// Проверка ветви x > 100 не должна подменять модель текущего пути
+	if sat, _ := s.CheckBranch(symbolic.NewBinaryOperation(x, intConst(100), symbolic.GT)); !sat {
+		t.Fatal("Expected x > 100 to be feasible")
+	}
+	after, err := s.Read([]*symbolic.SymbolicVariable{x})
+	if err != nil {
+		t.Fatalf("Error reading model: %v", err)
+	}
+	if after[x].(*big.Int).Cmp(before[x].(*big.Int)) != 0 {
+		t.Errorf("Expected x = %v after CheckBranch, got %v", before[x], after[x])
+	}
+}
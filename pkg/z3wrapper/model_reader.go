@@ -0,0 +1,430 @@
+package z3wrapper
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// ArrayEntry - явно заданный элемент массива в модели
+type ArrayEntry struct {
+	Index interface{}
+	Value interface{}
+}
+
+// ArrayValue - значение массива в модели: значение по умолчанию и явные записи (store)
+type ArrayValue struct {
+	Default interface{}
+	Entries []ArrayEntry
+}
+
+// Get возвращает элемент массива по индексу
+func (av *ArrayValue) Get(index interface{}) interface{} {
+	key := valueKey(index)
+	for _, e := range av.Entries {
+		if valueKey(e.Index) == key {
+			return e.Value
+		}
+	}
+	return av.Default
+}
+
+// String возвращает строковое представление массива
+func (av *ArrayValue) String() string {
+	var sb strings.Builder
+	sb.WriteString("[")
+	for _, e := range av.Entries {
+		sb.WriteString(fmt.Sprintf("%v: %v, ", e.Index, e.Value))
+	}
+	sb.WriteString(fmt.Sprintf("else: %v]", av.Default))
+	return sb.String()
+}
+
+// ObjectValue - значение объекта в модели: по массиву на каждое поле
+type ObjectValue struct {
+	Fields []interface{}
+}
+
+// BitVector - значение битового вектора фиксированной ширины
+type BitVector struct {
+	Width int
+	Value *big.Int // беззнаковое значение
+}
+
+// Signed возвращает значение, интерпретированное в дополнительном коде
+func (bv BitVector) Signed() *big.Int {
+	v := new(big.Int).Set(bv.Value)
+	if v.Bit(bv.Width-1) != 0 {
+		v.Sub(v, new(big.Int).Lsh(big.NewInt(1), uint(bv.Width)))
+	}
+	return v
+}
+
+// String возвращает строковое представление битового вектора
+func (bv BitVector) String() string {
+	return fmt.Sprintf("%s[%d]", bv.Value.String(), bv.Width)
+}
+
+// ModelReader извлекает из модели Z3 значения символьных переменных в виде Go значений:
+//   - IntType    -> *big.Int
+//   - BoolType   -> bool
+//   - ArrayType  -> *ArrayValue
+//
+// Объекты читаются по ссылке через ReadObject как *ObjectValue.
+// Битовые векторы читаются как BitVector, вещественные числа - как *big.Rat.
+//
+// Строки не поддерживаются: в go-z3 нет строкового сорта (и последовательностей),
+// поэтому ни транслятор, ни модель не содержат строковых значений, а значение
+// неизвестного сорта ReadValue возвращает как ошибку "unsupported sort"
+type ModelReader struct {
+	translator *translator.Z3Translator
+}
+
+// NewModelReader создаёт ModelReader; переменные транслируются тем же транслятором,
+// что и ограничения, поэтому модель должна быть получена в его контексте
+func NewModelReader(tr *translator.Z3Translator) *ModelReader {
+	return &ModelReader{translator: tr}
+}
+
+// Read извлекает значения всех переданных переменных
+func (r *ModelReader) Read(model *z3.Model, vars []*symbolic.SymbolicVariable) (map[*symbolic.SymbolicVariable]interface{}, error) {
+	res := make(map[*symbolic.SymbolicVariable]interface{}, len(vars))
+	for _, v := range vars {
+		value, err := r.ReadVariable(model, v)
+		if err != nil {
+			return nil, err
+		}
+		res[v] = value
+	}
+	return res, nil
+}
+
+// ReadVariable извлекает значение одной переменной
+func (r *ModelReader) ReadVariable(model *z3.Model, v *symbolic.SymbolicVariable) (interface{}, error) {
+	if v.Type() == symbolic.ObjectType {
+		return nil, fmt.Errorf("object variable %s has no value in the model, read objects with ReadObject", v.Name)
+	}
+
+	z, err := r.translator.TranslateExpression(v)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadValue(model, z.(z3.Value))
+}
+
+// ReadObject извлекает значения полей объекта по ссылке ref: поля читаются
+// из массивов кучи памяти транслятора по адресу объекта в модели
+func (r *ModelReader) ReadObject(model *z3.Model, ref *symbolic.Ref, fieldTypes []symbolic.InnerType) (*ObjectValue, error) {
+	mem := r.translator.Memory()
+	if mem == nil {
+		return nil, fmt.Errorf("memory is not set for reading %s", ref.String())
+	}
+	obj := &ObjectValue{}
+	for i, fieldTy := range fieldTypes {
+		z, err := r.translator.TranslateExpression(mem.PeekField(ref, i, fieldTy))
+		if err != nil {
+			return nil, err
+		}
+		field, err := r.ReadValue(model, z.(z3.Value))
+		if err != nil {
+			return nil, err
+		}
+		obj.Fields = append(obj.Fields, field)
+	}
+	return obj, nil
+}
+
+// ReadValue вычисляет выражение в модели и преобразует результат в Go значение
+func (r *ModelReader) ReadValue(model *z3.Model, value z3.Value) (interface{}, error) {
+	evaluated := model.Eval(value, true)
+	if evaluated == nil {
+		return nil, fmt.Errorf("cannot evaluate %s in model", value.String())
+	}
+
+	switch v := evaluated.(type) {
+	case z3.Int:
+		res, ok := v.AsBigInt()
+		if !ok {
+			return nil, fmt.Errorf("non-literal integer value %s", v.String())
+		}
+		return res, nil
+	case z3.Bool:
+		res, ok := v.AsBool()
+		if !ok {
+			return nil, fmt.Errorf("non-literal boolean value %s", v.String())
+		}
+		return res, nil
+	case z3.BV:
+		res, ok := v.AsBigUnsigned()
+		if !ok {
+			return nil, fmt.Errorf("non-literal bit-vector value %s", v.String())
+		}
+		return BitVector{Width: v.Sort().BVSize(), Value: res}, nil
+	case z3.Real:
+		res, ok := v.AsBigRat()
+		if !ok {
+			return nil, fmt.Errorf("non-rational real value %s", v.String())
+		}
+		return res, nil
+	case z3.Array:
+		return r.readArray(model, v)
+	default:
+		return nil, fmt.Errorf("unsupported sort %s of value %s", evaluated.Sort().String(), evaluated.String())
+	}
+}
+
+// readArray разбирает значение массива в модели: цепочку store над константным массивом
+// (или lambda-выражение) и вычисляет элементы по найденным в нём индексам
+func (r *ModelReader) readArray(model *z3.Model, arr z3.Array) (*ArrayValue, error) {
+	term, err := parseSExpr(arr.String())
+	if err != nil {
+		return nil, err
+	}
+	domain, _ := arr.Sort().DomainAndRange()
+
+	var indices []z3.Value
+	for _, idxTerm := range arrayIndices(term) {
+		if idx := literalValue(arr.Context(), domain, idxTerm); idx != nil {
+			indices = append(indices, idx)
+		}
+	}
+
+	// Вычисление (default ...) в модели не упрощается до литерала, поэтому значение
+	// по умолчанию читаем как элемент по индексу, не встречающемуся в массиве явно
+	res := &ArrayValue{}
+	if fresh := freshIndex(arr.Context(), domain, indices); fresh != nil {
+		if res.Default, err = r.ReadValue(model, arr.Select(fresh)); err != nil {
+			return nil, err
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, idx := range indices {
+		index, err := r.ReadValue(model, idx)
+		if err != nil {
+			return nil, err
+		}
+		if seen[valueKey(index)] {
+			continue
+		}
+		seen[valueKey(index)] = true
+
+		value, err := r.ReadValue(model, arr.Select(idx))
+		if err != nil {
+			return nil, err
+		}
+		if res.Default != nil && valueKey(value) == valueKey(res.Default) {
+			continue
+		}
+		res.Entries = append(res.Entries, ArrayEntry{Index: index, Value: value})
+	}
+	return res, nil
+}
+
+// freshIndex подбирает индекс сорта domain, отличный от всех indices
+// (nil, если все значения сорта уже использованы)
+func freshIndex(ctx *z3.Context, domain z3.Sort, indices []z3.Value) z3.Value {
+	used := make(map[string]bool)
+	for _, idx := range indices {
+		used[idx.String()] = true
+	}
+	switch domain.Kind() {
+	case z3.KindBool:
+		for _, b := range []bool{false, true} {
+			if v := ctx.FromBool(b); !used[v.String()] {
+				return v
+			}
+		}
+		return nil
+	case z3.KindInt, z3.KindBV:
+		for i := int64(0); i <= int64(len(indices)); i++ {
+			if v := ctx.FromInt(i, domain); !used[v.String()] {
+				return v
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// valueKey возвращает ключ для сравнения прочитанных значений
+func valueKey(v interface{}) string {
+	return fmt.Sprint(v)
+}
+
+// sexpr - S-выражение SMT-LIB: атом либо список
+type sexpr struct {
+	atom string
+	list []*sexpr
+}
+
+func (e *sexpr) isAtom() bool {
+	return e.list == nil
+}
+
+func (e *sexpr) head() string {
+	if e.isAtom() || len(e.list) == 0 || !e.list[0].isAtom() {
+		return ""
+	}
+	return e.list[0].atom
+}
+
+// parseSExpr разбирает S-выражение из текстового представления Z3
+func parseSExpr(text string) (*sexpr, error) {
+	tokens := tokenizeSExpr(text)
+	pos := 0
+	var parse func() (*sexpr, error)
+	parse = func() (*sexpr, error) {
+		if pos >= len(tokens) {
+			return nil, fmt.Errorf("unexpected end of s-expression %q", text)
+		}
+		tok := tokens[pos]
+		pos++
+		switch tok {
+		case "(":
+			e := &sexpr{list: []*sexpr{}}
+			for pos < len(tokens) && tokens[pos] != ")" {
+				child, err := parse()
+				if err != nil {
+					return nil, err
+				}
+				e.list = append(e.list, child)
+			}
+			if pos >= len(tokens) {
+				return nil, fmt.Errorf("unbalanced s-expression %q", text)
+			}
+			pos++
+			return e, nil
+		case ")":
+			return nil, fmt.Errorf("unexpected ')' in s-expression %q", text)
+		default:
+			return &sexpr{atom: tok}, nil
+		}
+	}
+	return parse()
+}
+
+func tokenizeSExpr(text string) []string {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+		case c == ' ' || c == '\n' || c == '\t' || c == '\r':
+			i++
+		case c == '|' || c == '"':
+			// Символы в |...| и строковые литералы читаются целиком
+			j := i + 1
+			for j < len(text) && text[j] != c {
+				j++
+			}
+			if j < len(text) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			j := i
+			for j < len(text) && !strings.ContainsRune("() \n\t\r", rune(text[j])) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		}
+	}
+	return tokens
+}
+
+// arrayIndices собирает индексы, явно встречающиеся в значении массива
+func arrayIndices(term *sexpr) []*sexpr {
+	switch term.head() {
+	case "store":
+		if len(term.list) != 4 {
+			return nil
+		}
+		return append(arrayIndices(term.list[1]), term.list[2])
+	case "lambda":
+		// (lambda ((x Int)) (ite (= x 1) 5 ...)) - кандидаты в индексы все литералы тела
+		if len(term.list) != 3 {
+			return nil
+		}
+		return literalsOf(term.list[2])
+	default:
+		return nil
+	}
+}
+
+func literalsOf(term *sexpr) []*sexpr {
+	if literalText(term) != "" {
+		return []*sexpr{term}
+	}
+	var res []*sexpr
+	for _, child := range term.list {
+		res = append(res, literalsOf(child)...)
+	}
+	return res
+}
+
+// literalText возвращает текст числового/булевого литерала или "" для прочих выражений
+func literalText(term *sexpr) string {
+	if term.isAtom() {
+		a := term.atom
+		if a == "true" || a == "false" || strings.HasPrefix(a, "#x") || strings.HasPrefix(a, "#b") {
+			return a
+		}
+		if _, ok := new(big.Int).SetString(a, 10); ok {
+			return a
+		}
+		return ""
+	}
+	if term.head() == "-" && len(term.list) == 2 && term.list[1].isAtom() {
+		if _, ok := new(big.Int).SetString(term.list[1].atom, 10); ok {
+			return "-" + term.list[1].atom
+		}
+	}
+	if term.head() == "_" && len(term.list) == 3 && strings.HasPrefix(term.list[1].atom, "bv") {
+		return term.list[1].atom
+	}
+	return ""
+}
+
+// literalValue строит значение Z3 сорта sort по литералу из S-выражения
+func literalValue(ctx *z3.Context, sort z3.Sort, term *sexpr) z3.Value {
+	text := literalText(term)
+	if text == "" {
+		return nil
+	}
+	switch sort.Kind() {
+	case z3.KindBool:
+		if text == "true" || text == "false" {
+			return ctx.FromBool(text == "true")
+		}
+	case z3.KindInt:
+		if v, ok := new(big.Int).SetString(text, 10); ok {
+			return ctx.FromBigInt(v, sort)
+		}
+	case z3.KindBV:
+		var v *big.Int
+		var ok bool
+		switch {
+		case strings.HasPrefix(text, "#x"):
+			v, ok = new(big.Int).SetString(text[2:], 16)
+		case strings.HasPrefix(text, "#b"):
+			v, ok = new(big.Int).SetString(text[2:], 2)
+		case strings.HasPrefix(text, "bv"):
+			v, ok = new(big.Int).SetString(text[2:], 10)
+		}
+		if ok {
+			return ctx.FromBigInt(v, sort)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package z3wrapper
+
+import (
+	"math/big"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func TestGetIntValueNegative(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+
+	x := solver.CreateIntVar("x")
+	solver.Assert(x.Eq(solver.CreateIntLit(-5)))
+
+	if sat, _ := solver.IsSatisfiable(); !sat {
+		t.Fatal("Expected satisfiable constraints")
+	}
+	xVal, err := solver.GetIntValue(solver.Model(), x)
+	if err != nil {
+		t.Fatalf("Error getting x value: %v", err)
+	}
+	if xVal != -5 {
+		t.Errorf("Expected x = -5, got %d", xVal)
+	}
+}
+
+func TestModelReaderPrimitivesAndArrays(t *testing.T) {
+	tr := translator.NewZ3Translator(nil)
+	solver := NewSolverWithContext(tr.GetContext())
+
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	flag := symbolic.NewSymbolicVariable("flag", symbolic.BoolType)
+	arr := symbolic.NewSymbolicVariableArray("arr", symbolic.InnerType{ExprTy: symbolic.IntType})
+
+	// x = -(10^20), flag, arr[-2] = x, arr[3] = 7
+	huge, _ := new(big.Int).SetString("-100000000000000000000", 10)
+	hugeLit := tr.GetContext().FromBigInt(huge, tr.GetContext().IntSort()).(z3.Int)
+	xZ3, _ := tr.TranslateExpression(x)
+	solver.Assert(xZ3.(z3.Int).Eq(hugeLit))
+	flagZ3, _ := tr.TranslateExpression(flag)
+	solver.Assert(flagZ3.(z3.Bool))
+	for _, c := range []symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(symbolic.NewBinaryOperation(arr, symbolic.NewIntConstant(-2), symbolic.SELECT), x, symbolic.EQ),
+		symbolic.NewBinaryOperation(symbolic.NewBinaryOperation(arr, symbolic.NewIntConstant(3), symbolic.SELECT), symbolic.NewIntConstant(7), symbolic.EQ),
+	} {
+		z, _ := tr.TranslateExpression(c)
+		solver.Assert(z.(z3.Bool))
+	}
+
+	if sat, err := solver.IsSatisfiable(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+	values, err := NewModelReader(tr).Read(solver.Model(), []*symbolic.SymbolicVariable{x, flag, arr})
+	if err != nil {
+		t.Fatalf("Error reading model: %v", err)
+	}
+
+	if values[x].(*big.Int).Cmp(huge) != 0 {
+		t.Errorf("Expected x = %v, got %v", huge, values[x])
+	}
+	if values[flag] != true {
+		t.Errorf("Expected flag = true, got %v", values[flag])
+	}
+	arrVal := values[arr].(*ArrayValue)
+	if arrVal.Get(big.NewInt(-2)).(*big.Int).Cmp(huge) != 0 {
+		t.Errorf("Expected arr[-2] = %v, got %v", huge, arrVal)
+	}
+	if arrVal.Get(big.NewInt(3)).(*big.Int).Int64() != 7 {
+		t.Errorf("Expected arr[3] = 7, got %v", arrVal)
+	}
+}
+
+func TestModelReaderBitVector(t *testing.T) {
+	ctx := z3.NewContext(nil)
+	solver := NewSolverWithContext(ctx)
+	bv := ctx.BVConst("bv", 8)
+	solver.Assert(bv.Eq(ctx.FromInt(-3, ctx.BVSort(8)).(z3.BV)))
+
+	if sat, _ := solver.IsSatisfiable(); !sat {
+		t.Fatal("Expected satisfiable constraints")
+	}
+	value, err := NewModelReader(translator.NewZ3TranslatorWithContext(ctx, nil)).ReadValue(solver.Model(), bv)
+	if err != nil {
+		t.Fatalf("Error reading model: %v", err)
+	}
+	if got := value.(BitVector); got.Value.Int64() != 253 || got.Signed().Int64() != -3 {
+		t.Errorf("Expected bv = 253 (-3 signed), got %v", got)
+	}
+}
+
+func TestReadObjectHasNoSideEffects(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	tr := translator.NewZ3Translator(mem)
+	solver := NewSolverWithContext(tr.GetContext())
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// func f(p *Point) { if p != nil { p.x = 3 } }
+	p := mem.InputPointer("p", "Point")
+	mem.AssignField(p, 0, symbolic.NewIntConstant(3))
+	for _, c := range append(mem.Constraints(), symbolic.NewBinaryOperation(p, symbolic.NewNilRef("Point"), symbolic.NE)) {
+		z, _ := tr.TranslateExpression(c)
+		solver.Assert(z.(z3.Bool))
+	}
+	if sat, err := solver.IsSatisfiable(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+
+	panics, heap := len(mem.Panics()), len(mem.Heap)
+	obj, err := NewModelReader(tr).ReadObject(solver.Model(), p, []symbolic.InnerType{intTy, intTy})
+	if err != nil {
+		t.Fatalf("Error reading object: %v", err)
+	}
+	if obj.Fields[0].(*big.Int).Int64() != 3 {
+		t.Errorf("Expected p.x = 3, got %v", obj.Fields[0])
+	}
+	if len(mem.Panics()) != panics || len(mem.Heap) != heap {
+		t.Errorf("Expected reading the model not to change memory, got %d panics and %d heap arrays", len(mem.Panics()), len(mem.Heap))
+	}
+}
@@ -0,0 +1,365 @@
+package z3wrapper
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// OptimizationMode задаёт способ совместной оптимизации нескольких целей
+type OptimizationMode int
+
+const (
+	// Lexicographic оптимизирует цели по очереди в порядке их добавления
+	Lexicographic OptimizationMode = iota
+	// Pareto перечисляет Парето-оптимальные решения
+	Pareto
+)
+
+// String возвращает строковое представление режима
+func (m OptimizationMode) String() string {
+	switch m {
+	case Lexicographic:
+		return "lexicographic"
+	case Pareto:
+		return "pareto"
+	default:
+		return "unknown"
+	}
+}
+
+// DefaultOptimizationSteps - ограничение числа запросов к solver'у на одну цель
+const DefaultOptimizationSteps = 128
+
+// ErrUnbounded возвращается, если за отведённое число шагов оптимум не доказан
+// (например, цель не ограничена)
+var ErrUnbounded = errors.New("objective is unbounded or optimum was not reached")
+
+// objective - цель оптимизации (минимизация или максимизация целочисленного выражения)
+type objective struct {
+	expr     z3.Int
+	maximize bool
+}
+
+// Optimizer ищет модели, оптимальные по заданным целям.
+//
+// Обёртка go-z3 не предоставляет Z3 Optimize, поэтому оптимум ищется поверх
+// обычного solver'а: после каждой найденной модели добавляется ограничение
+// "цель строго лучше", шаг улучшения удваивается, а после первого unsat
+// интервал сужается двоичным поиском.
+type Optimizer struct {
+	ctx        *z3.Context
+	translator *translator.Z3Translator
+	assertions []z3.Bool
+	objectives []objective
+	mode       OptimizationMode
+	maxSteps   int
+
+	model  *z3.Model
+	values []*big.Int
+
+	// Ограничения, отсекающие уже найденные точки фронта Парето
+	paretoBlocks []z3.Bool
+}
+
+// NewOptimizer создаёт оптимизатор над заданным контекстом Z3
+func NewOptimizer(ctx *z3.Context) *Optimizer {
+	return &Optimizer{
+		ctx:        ctx,
+		translator: translator.NewZ3TranslatorWithContext(ctx, nil),
+		mode:       Lexicographic,
+		maxSteps:   DefaultOptimizationSteps,
+	}
+}
+
+// SetMode задаёт режим совместной оптимизации целей
+func (o *Optimizer) SetMode(mode OptimizationMode) {
+	o.mode = mode
+	o.paretoBlocks = nil
+}
+
+// SetMaxSteps ограничивает число запросов к solver'у на одну цель
+func (o *Optimizer) SetMaxSteps(steps int) {
+	o.maxSteps = steps
+}
+
+// Translator возвращает транслятор оптимизатора (он использует тот же контекст)
+func (o *Optimizer) Translator() *translator.Z3Translator {
+	return o.translator
+}
+
+// Assert добавляет ограничение, которому должна удовлетворять модель
+func (o *Optimizer) Assert(constraint symbolic.SymbolicExpression) error {
+	if constraint.Type() != symbolic.BoolType {
+		return fmt.Errorf("constraint %s is not boolean", constraint.String())
+	}
+	z, err := o.translator.TranslateExpression(constraint)
+	if err != nil {
+		return err
+	}
+	o.assertions = append(o.assertions, z.(z3.Bool))
+	o.paretoBlocks = nil
+	return nil
+}
+
+// Minimize добавляет цель минимизации целочисленного выражения
+func (o *Optimizer) Minimize(expr symbolic.SymbolicExpression) error {
+	return o.addObjective(expr, false)
+}
+
+// Maximize добавляет цель максимизации целочисленного выражения
+func (o *Optimizer) Maximize(expr symbolic.SymbolicExpression) error {
+	return o.addObjective(expr, true)
+}
+
+// MinimizeAbs добавляет цель минимизации модуля выражения
+func (o *Optimizer) MinimizeAbs(expr symbolic.SymbolicExpression) error {
+	if expr.Type() != symbolic.IntType {
+		return fmt.Errorf("objective %s is not integer", expr.String())
+	}
+	isNeg := symbolic.NewBinaryOperation(expr, symbolic.NewIntConstant(0), symbolic.LT)
+	neg := symbolic.NewUnaryOperation(symbolic.UN_SUB, expr)
+	return o.addObjective(symbolic.NewTernaryOperation(isNeg, neg, expr), false)
+}
+
+func (o *Optimizer) addObjective(expr symbolic.SymbolicExpression, maximize bool) error {
+	if expr.Type() != symbolic.IntType {
+		return fmt.Errorf("objective %s is not integer", expr.String())
+	}
+	z, err := o.translator.TranslateExpression(expr)
+	if err != nil {
+		return err
+	}
+	o.objectives = append(o.objectives, objective{expr: z.(z3.Int), maximize: maximize})
+	o.paretoBlocks = nil
+	return nil
+}
+
+// Check ищет оптимальную модель для добавленных ограничений.
+// В режиме Pareto каждый вызов возвращает следующую точку фронта Парето,
+// пока они не закончатся. Если оптимум не доказан за отведённое число шагов,
+// модель (лучшая из найденных) всё равно доступна, а возвращается ErrUnbounded
+func (o *Optimizer) Check() (bool, error) {
+	return o.check(o.assertions)
+}
+
+// Model возвращает модель, найденную последним вызовом Check
+func (o *Optimizer) Model() *z3.Model {
+	return o.model
+}
+
+// Values возвращает значения целей в последней найденной модели
+// (в порядке добавления целей)
+func (o *Optimizer) Values() []*big.Int {
+	return o.values
+}
+
+// SelectModel реализует ModelPolicy: возвращает оптимальную модель среди
+// удовлетворяющих ограничениям solver'а и ограничениям оптимизатора
+func (o *Optimizer) SelectModel(s *Solver) (*z3.Model, error) {
+	if s.Context() != o.ctx {
+		return nil, fmt.Errorf("optimizer and solver use different Z3 contexts")
+	}
+	assertions := append(s.Assertions(), o.assertions...)
+	mode := o.mode
+	o.mode = Lexicographic
+	defer func() { o.mode = mode }()
+
+	sat, err := o.check(assertions)
+	if err != nil && !errors.Is(err, ErrUnbounded) {
+		return nil, err
+	}
+	if !sat {
+		return nil, fmt.Errorf("constraints are unsatisfiable")
+	}
+	return o.model, nil
+}
+
+func (o *Optimizer) check(assertions []z3.Bool) (bool, error) {
+	s := z3.NewSolver(o.ctx)
+	for _, a := range assertions {
+		s.Assert(a)
+	}
+	switch o.mode {
+	case Lexicographic:
+		return o.checkLexicographic(s)
+	case Pareto:
+		return o.checkPareto(s)
+	default:
+		return false, fmt.Errorf("unknown optimization mode %s", o.mode)
+	}
+}
+
+// checkLexicographic оптимизирует цели по очереди, фиксируя достигнутые значения
+func (o *Optimizer) checkLexicographic(s *z3.Solver) (bool, error) {
+	sat, err := s.Check()
+	if err != nil || !sat {
+		return false, err
+	}
+	o.model = s.Model()
+
+	var unbounded bool
+	for _, obj := range o.objectives {
+		best, err := o.optimize(s, obj)
+		if errors.Is(err, ErrUnbounded) {
+			unbounded = true
+		} else if err != nil {
+			return false, err
+		}
+		s.Assert(obj.expr.Eq(o.intLit(best)))
+	}
+	o.values = o.objectiveValues(o.model)
+	if unbounded {
+		return true, ErrUnbounded
+	}
+	return true, nil
+}
+
+// optimize находит оптимальное значение цели при текущих ограничениях s.
+// На входе o.model должна удовлетворять ограничениям s; на выходе это модель с оптимумом
+func (o *Optimizer) optimize(s *z3.Solver, obj objective) (*big.Int, error) {
+	best := o.eval(o.model, obj)
+	// Значения строже, чем bound, недостижимы (nil - граница ещё не известна)
+	var bound *big.Int
+	step := big.NewInt(1)
+
+	for i := 0; i < o.maxSteps; i++ {
+		var target *big.Int
+		if bound == nil {
+			target = o.improve(best, step, obj.maximize)
+			step.Lsh(step, 1)
+		} else {
+			if bound.Cmp(best) == 0 {
+				return best, nil
+			}
+			// Середина интервала между недостижимой границей и лучшим значением
+			target = new(big.Int).Add(bound, best)
+			if obj.maximize {
+				target.Add(target, big.NewInt(1))
+			}
+			target.Rsh(target, 1)
+			if target.Cmp(best) == 0 {
+				target = o.improve(best, big.NewInt(1), obj.maximize)
+			}
+		}
+
+		s.Push()
+		s.Assert(o.atLeast(obj, target))
+		sat, err := s.Check()
+		if err != nil {
+			s.Pop()
+			return best, err
+		}
+		if sat {
+			o.model = s.Model()
+			best = o.eval(o.model, obj)
+		} else {
+			bound = o.improve(target, big.NewInt(-1), obj.maximize)
+		}
+		s.Pop()
+	}
+	if bound != nil && bound.Cmp(best) == 0 {
+		return best, nil
+	}
+	return best, ErrUnbounded
+}
+
+// checkPareto находит следующую точку фронта Парето, не доминируемую уже найденными
+func (o *Optimizer) checkPareto(s *z3.Solver) (bool, error) {
+	for _, block := range o.paretoBlocks {
+		s.Assert(block)
+	}
+	sat, err := s.Check()
+	if err != nil || !sat {
+		return false, err
+	}
+	o.model = s.Model()
+	values := o.objectiveValues(o.model)
+
+	unbounded := true
+	for i := 0; i < o.maxSteps; i++ {
+		s.Push()
+		s.Assert(o.dominates(values))
+		sat, err := s.Check()
+		if err != nil {
+			s.Pop()
+			return false, err
+		}
+		if !sat {
+			s.Pop()
+			unbounded = false
+			break
+		}
+		o.model = s.Model()
+		values = o.objectiveValues(o.model)
+		s.Pop()
+	}
+	o.values = values
+
+	// Следующие точки должны быть строго лучше найденной хотя бы по одной цели
+	var better []z3.Bool
+	for i, obj := range o.objectives {
+		better = append(better, o.atLeast(obj, o.improve(values[i], big.NewInt(1), obj.maximize)))
+	}
+	if len(better) > 0 {
+		o.paretoBlocks = append(o.paretoBlocks, better[0].Or(better[1:]...))
+	} else {
+		o.paretoBlocks = append(o.paretoBlocks, o.ctx.FromBool(false))
+	}
+
+	if unbounded {
+		return true, ErrUnbounded
+	}
+	return true, nil
+}
+
+// dominates строит условие "решение не хуже values по всем целям и строго лучше хотя бы по одной"
+func (o *Optimizer) dominates(values []*big.Int) z3.Bool {
+	var notWorse, better []z3.Bool
+	for i, obj := range o.objectives {
+		notWorse = append(notWorse, o.atLeast(obj, values[i]))
+		better = append(better, o.atLeast(obj, o.improve(values[i], big.NewInt(1), obj.maximize)))
+	}
+	if len(better) == 0 {
+		return o.ctx.FromBool(false)
+	}
+	return better[0].Or(better[1:]...).And(notWorse...)
+}
+
+// atLeast строит условие "цель не хуже value"
+func (o *Optimizer) atLeast(obj objective, value *big.Int) z3.Bool {
+	if obj.maximize {
+		return obj.expr.GE(o.intLit(value))
+	}
+	return obj.expr.LE(o.intLit(value))
+}
+
+// improve сдвигает значение на delta в сторону улучшения цели
+func (o *Optimizer) improve(value, delta *big.Int, maximize bool) *big.Int {
+	if maximize {
+		return new(big.Int).Add(value, delta)
+	}
+	return new(big.Int).Sub(value, delta)
+}
+
+func (o *Optimizer) eval(model *z3.Model, obj objective) *big.Int {
+	value, _ := model.Eval(obj.expr, true).(z3.Int).AsBigInt()
+	return value
+}
+
+func (o *Optimizer) objectiveValues(model *z3.Model) []*big.Int {
+	var values []*big.Int
+	for _, obj := range o.objectives {
+		values = append(values, o.eval(model, obj))
+	}
+	return values
+}
+
+func (o *Optimizer) intLit(value *big.Int) z3.Int {
+	return o.ctx.FromBigInt(value, o.ctx.IntSort()).(z3.Int)
+}
@@ -0,0 +1,155 @@
+package z3wrapper
+
+import (
+	"errors"
+	"testing"
+
+	"symbolic-execution-course/internal/symbolic"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+func intConst(v int64) *symbolic.IntConstant {
+	return symbolic.NewIntConstant(v)
+}
+
+func TestOptimizerMinimizeMaximize(t *testing.T) {
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+
+	for _, maximize := range []bool{false, true} {
+		opt := NewOptimizer(z3.NewContext(nil))
+		// 3 <= x <= 100
+		opt.Assert(symbolic.NewBinaryOperation(x, intConst(3), symbolic.GE))
+		opt.Assert(symbolic.NewBinaryOperation(x, intConst(100), symbolic.LE))
+
+		expected := int64(3)
+		if maximize {
+			expected = 100
+			opt.Maximize(x)
+		} else {
+			opt.Minimize(x)
+		}
+
+		sat, err := opt.Check()
+		if err != nil {
+			t.Fatalf("Error optimizing: %v", err)
+		}
+		if !sat {
+			t.Fatal("Expected satisfiable constraints")
+		}
+		if got := opt.Values()[0].Int64(); got != expected {
+			t.Errorf("Expected x = %d, got %d", expected, got)
+		}
+	}
+}
+
+func TestOptimizerMinimizeAbs(t *testing.T) {
+	opt := NewOptimizer(z3.NewContext(nil))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+
+	// -50 < x < -7: минимальный модуль у x = -8
+	opt.Assert(symbolic.NewBinaryOperation(x, intConst(-50), symbolic.GT))
+	opt.Assert(symbolic.NewBinaryOperation(x, intConst(-7), symbolic.LT))
+	opt.MinimizeAbs(x)
+
+	if sat, err := opt.Check(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+	if got := opt.Values()[0].Int64(); got != 8 {
+		t.Errorf("Expected |x| = 8, got %d", got)
+	}
+}
+
+func TestOptimizerLexicographic(t *testing.T) {
+	opt := NewOptimizer(z3.NewContext(nil))
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+
+	// x <= 5, x + y >= 10: сначала максимизируем x, затем минимизируем y
+	opt.Assert(symbolic.NewBinaryOperation(x, intConst(5), symbolic.LE))
+	sum := symbolic.NewBinaryOperation(x, y, symbolic.ADD)
+	opt.Assert(symbolic.NewBinaryOperation(sum, intConst(10), symbolic.GE))
+	opt.Maximize(x)
+	opt.Minimize(y)
+
+	if sat, err := opt.Check(); err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+	values := opt.Values()
+	if values[0].Int64() != 5 || values[1].Int64() != 5 {
+		t.Errorf("Expected x = 5, y = 5, got x = %v, y = %v", values[0], values[1])
+	}
+}
+
+func TestOptimizerUnbounded(t *testing.T) {
+	opt := NewOptimizer(z3.NewContext(nil))
+	opt.SetMaxSteps(16)
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	opt.Minimize(x)
+
+	sat, err := opt.Check()
+	if !sat {
+		t.Fatal("Expected satisfiable constraints")
+	}
+	if !errors.Is(err, ErrUnbounded) {
+		t.Errorf("Expected ErrUnbounded, got %v", err)
+	}
+}
+
+func TestOptimizerPareto(t *testing.T) {
+	opt := NewOptimizer(z3.NewContext(nil))
+	opt.SetMode(Pareto)
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	y := symbolic.NewSymbolicVariable("y", symbolic.IntType)
+
+	// x, y >= 0, x + y <= 4: фронт Парето для max x, max y - пять точек x + y = 4
+	opt.Assert(symbolic.NewBinaryOperation(x, intConst(0), symbolic.GE))
+	opt.Assert(symbolic.NewBinaryOperation(y, intConst(0), symbolic.GE))
+	sum := symbolic.NewBinaryOperation(x, y, symbolic.ADD)
+	opt.Assert(symbolic.NewBinaryOperation(sum, intConst(4), symbolic.LE))
+	opt.Maximize(x)
+	opt.Maximize(y)
+
+	points := 0
+	for {
+		sat, err := opt.Check()
+		if err != nil {
+			t.Fatalf("Error optimizing: %v", err)
+		}
+		if !sat {
+			break
+		}
+		values := opt.Values()
+		if values[0].Int64()+values[1].Int64() != 4 {
+			t.Errorf("Point (%v, %v) is not Pareto-optimal", values[0], values[1])
+		}
+		points++
+	}
+	if points != 5 {
+		t.Errorf("Expected 5 Pareto-optimal points, got %d", points)
+	}
+}
+
+func TestOptimizerAsModelPolicy(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+
+	x := solver.CreateIntVar("x")
+	solver.Assert(x.GT(solver.CreateIntLit(10)))
+
+	opt := NewOptimizer(solver.Context())
+	opt.Minimize(symbolic.NewSymbolicVariable("x", symbolic.IntType))
+	solver.SetModelPolicy(opt)
+
+	sat, err := solver.IsSatisfiable()
+	if err != nil || !sat {
+		t.Fatalf("Expected satisfiable constraints, got %v, %v", sat, err)
+	}
+	xVal, err := solver.GetIntValue(solver.Model(), x)
+	if err != nil {
+		t.Fatalf("Error getting x value: %v", err)
+	}
+	if xVal != 11 {
+		t.Errorf("Expected minimal x = 11, got %d", xVal)
+	}
+}
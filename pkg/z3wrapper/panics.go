@@ -0,0 +1,60 @@
+package z3wrapper
+
+import (
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// PanicWitness - достижимая паника времени исполнения: запрос нарушения и
+// значения входных переменных из его модели
+type PanicWitness struct {
+	Panic  memory.RuntimePanic
+	Query  symbolic.SymbolicExpression
+	Values map[*symbolic.SymbolicVariable]interface{}
+}
+
+// FindPanics проверяет все возможные паники памяти mem на путях, где они были
+// записаны, с дополнительными условиями path и возвращает достижимые вместе
+// со свидетелями. Транслятор tr должен использовать ту же память. Свидетель
+// содержит значения переменных условия паники и пути, кроме массивов кучи
+func FindPanics(tr *translator.Z3Translator, mem *memory.SymbolicMemory, path ...symbolic.SymbolicExpression) ([]PanicWitness, error) {
+	reader := NewModelReader(tr)
+	var res []PanicWitness
+	for _, rp := range mem.Panics() {
+		query := mem.PanicQuery(rp, path...)
+		z, err := tr.TranslateExpression(query)
+		if err != nil {
+			return nil, err
+		}
+		solver := NewSolverWithContext(tr.GetContext())
+		solver.Assert(z.(z3.Bool))
+		sat, err := solver.Check()
+		if err != nil {
+			return nil, err
+		}
+		if !sat {
+			continue
+		}
+
+		var inputs []*symbolic.SymbolicVariable
+		operands := append([]symbolic.SymbolicExpression{}, path...)
+		if rp.Path != nil {
+			operands = append(operands, rp.Path)
+		}
+		relevant := symbolic.NewLogicalOperation(append(operands, rp.Cond), symbolic.AND)
+		for _, v := range symbolic.FreeVariables(relevant) {
+			if v.Type() != symbolic.ArrayType {
+				inputs = append(inputs, v)
+			}
+		}
+		values, err := reader.Read(solver.Model(), inputs)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, PanicWitness{Panic: rp, Query: query, Values: values})
+	}
+	return res, nil
+}
@@ -0,0 +1,79 @@
+package z3wrapper
+
+import (
+	"math/big"
+	"testing"
+
+	"symbolic-execution-course/internal/memory"
+	"symbolic-execution-course/internal/symbolic"
+	"symbolic-execution-course/internal/translator"
+)
+
+func TestFindPanicsWitness(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	tr := translator.NewZ3Translator(mem)
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// var arr [5]int; if i > 2 { arr[i] = 1 }
+	i := symbolic.NewSymbolicVariable("i", symbolic.IntType)
+	arr := mem.AllocateArray(intTy, symbolic.NewIntConstant(5))
+	mem.AssignToArray(arr, i, symbolic.NewIntConstant(1))
+
+	witnesses, err := FindPanics(tr, mem, symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(2), symbolic.GT))
+	if err != nil {
+		t.Fatalf("Error checking panics: %v", err)
+	}
+	if len(witnesses) != 1 || witnesses[0].Panic.Kind != memory.IndexOutOfRange {
+		t.Fatalf("Expected a single index out of range, got %v", witnesses)
+	}
+	if len(witnesses[0].Values) != 1 || witnesses[0].Values[i].(*big.Int).Int64() < 5 {
+		t.Errorf("Expected witness i >= 5, got %v", witnesses[0].Values)
+	}
+
+	witnesses, err = FindPanics(tr, mem, symbolic.NewBinaryOperation(i, symbolic.NewIntConstant(4), symbolic.EQ))
+	if err != nil {
+		t.Fatalf("Error checking panics: %v", err)
+	}
+	if len(witnesses) != 0 {
+		t.Errorf("Expected arr[4] to be safe, got %v", witnesses)
+	}
+}
+
+func TestFindPanicsUsesPathOfOperation(t *testing.T) {
+	mem := memory.NewSymbolicMemory()
+	intTy := symbolic.InnerType{ExprTy: symbolic.IntType}
+
+	// var arr [5]int; if 0 <= x && x < 5 { arr[x] = 1 } else { arr[x-5] = 1 }
+	x := symbolic.NewSymbolicVariable("x", symbolic.IntType)
+	arr := mem.AllocateArray(intTy, symbolic.NewIntConstant(5))
+	inRange := symbolic.NewLogicalOperation([]symbolic.SymbolicExpression{
+		symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(0), symbolic.GE),
+		symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(5), symbolic.LT),
+	}, symbolic.AND)
+
+	thenMem, elseMem := mem.Fork(), mem.Fork()
+	thenMem.AssumePath(inRange)
+	thenMem.AssignToArray(arr, x, symbolic.NewIntConstant(1))
+	elseMem.AssumePath(symbolic.NewUnaryOperation(symbolic.UN_NOT, inRange))
+	elseMem.AssignToArray(arr, symbolic.NewBinaryOperation(x, symbolic.NewIntConstant(5), symbolic.SUB), symbolic.NewIntConstant(1))
+
+	witnesses, err := FindPanics(translator.NewZ3Translator(thenMem), thenMem)
+	if err != nil {
+		t.Fatalf("Error checking panics: %v", err)
+	}
+	if len(witnesses) != 0 {
+		t.Errorf("Expected arr[x] to be safe under its branch condition, got %v", witnesses)
+	}
+
+	merged := memory.Merge(inRange, thenMem, elseMem)
+	witnesses, err = FindPanics(translator.NewZ3Translator(merged), merged)
+	if err != nil {
+		t.Fatalf("Error checking panics: %v", err)
+	}
+	if len(witnesses) != 1 || witnesses[0].Panic.Kind != memory.IndexOutOfRange {
+		t.Fatalf("Expected a single index out of range in the else branch, got %v", witnesses)
+	}
+	if v := witnesses[0].Values[x].(*big.Int).Int64(); v >= 0 && v < 10 {
+		t.Errorf("Expected witness with x-5 out of range, got x = %d", v)
+	}
+}
@@ -4,14 +4,26 @@ package z3wrapper
 
 import (
 	"fmt"
+	"io"
+	"math/big"
+
 	"github.com/ebukreev/go-z3/z3"
-	"strconv"
 )
 
 // Solver представляет обёртку над Z3 solver
 type Solver struct {
-	ctx    *z3.Context
-	solver *z3.Solver
+	ctx       *z3.Context
+	solver    *z3.Solver
+	policy    ModelPolicy
+	stats     SolverStats
+	statsOpts StatsOptions
+	log       io.Writer
+}
+
+// ModelPolicy выбирает, какую из моделей, удовлетворяющих ограничениям solver'а,
+// вернуть пользователю (например, модель с минимальными значениями входов)
+type ModelPolicy interface {
+	SelectModel(s *Solver) (*z3.Model, error)
 }
 
 // NewSolver создаёт новый экземпляр Z3 solver
@@ -26,6 +38,15 @@ func NewSolver() *Solver {
 	}
 }
 
+// NewSolverWithContext создаёт solver поверх существующего контекста Z3,
+// например, контекста translator.Z3Translator
+func NewSolverWithContext(ctx *z3.Context) *Solver {
+	return &Solver{
+		ctx:    ctx,
+		solver: z3.NewSolver(ctx),
+	}
+}
+
 // Close освобождает ресурсы solver'а
 func (s *Solver) Close() {
 	// В этой версии Z3 нет метода Close для solver и context
@@ -44,15 +65,30 @@ func (s *Solver) Assert(constraint z3.Bool) {
 
 // Check проверяет выполнимость текущих ограничений
 func (s *Solver) Check() (bool, error) {
-	sat, err := s.solver.Check()
-	return sat, err
+	return s.check()
 }
 
-// Model возвращает модель, если ограничения выполнимы
+// Model возвращает модель, если ограничения выполнимы.
+// Если задана политика выбора модели, модель выбирается ею
 func (s *Solver) Model() *z3.Model {
+	if s.policy != nil {
+		if model, err := s.policy.SelectModel(s); err == nil && model != nil {
+			return model
+		}
+	}
 	return s.solver.Model()
 }
 
+// SetModelPolicy задаёт политику выбора модели (nil - модель Z3 по умолчанию)
+func (s *Solver) SetModelPolicy(policy ModelPolicy) {
+	s.policy = policy
+}
+
+// Assertions возвращает текущие ограничения solver'а
+func (s *Solver) Assertions() []z3.Bool {
+	return s.solver.Assertions()
+}
+
 // Push сохраняет текущее состояние solver'а
 func (s *Solver) Push() {
 	s.solver.Push()
@@ -80,41 +116,52 @@ func (s *Solver) CreateIntLit(value int64) z3.Int {
 
 // IsSatisfiable проверяет, выполнимы ли текущие ограничения
 func (s *Solver) IsSatisfiable() (bool, error) {
-	return s.solver.Check()
+	return s.check()
 }
 
 // GetIntValue получает значение целочисленной переменной из модели
 func (s *Solver) GetIntValue(model *z3.Model, variable z3.Int) (int64, error) {
-	value := model.Eval(variable, false)
+	value := model.Eval(variable, true)
 	if value == nil {
 		return 0, fmt.Errorf("variable not found in model")
 	}
 
-	// Конвертируем в строку и затем в int64
-	str := value.String()
-	result, err := strconv.ParseInt(str, 10, 64)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse integer value: %v", err)
+	// Отрицательные числа печатаются как "(- 5)", поэтому берём числовое значение напрямую
+	result, ok := value.(z3.Int).AsBigInt()
+	if !ok {
+		return 0, fmt.Errorf("failed to parse integer value: %s", value.String())
+	}
+	if !result.IsInt64() {
+		return 0, fmt.Errorf("integer value %s does not fit into int64", result.String())
+	}
+
+	return result.Int64(), nil
+}
+
+// GetBigIntValue получает значение целочисленной переменной произвольной величины
+func (s *Solver) GetBigIntValue(model *z3.Model, variable z3.Int) (*big.Int, error) {
+	value := model.Eval(variable, true)
+	if value == nil {
+		return nil, fmt.Errorf("variable not found in model")
 	}
 
+	result, ok := value.(z3.Int).AsBigInt()
+	if !ok {
+		return nil, fmt.Errorf("failed to parse integer value: %s", value.String())
+	}
 	return result, nil
 }
 
 // GetBoolValue получает значение булевой переменной из модели
 func (s *Solver) GetBoolValue(model *z3.Model, variable z3.Bool) (bool, error) {
-	value := model.Eval(variable, false)
+	value := model.Eval(variable, true)
 	if value == nil {
 		return false, fmt.Errorf("variable not found in model")
 	}
 
-	// Проверяем строковое представление
-	str := value.String()
-	switch str {
-	case "true":
-		return true, nil
-	case "false":
-		return false, nil
-	default:
-		return false, fmt.Errorf("unexpected boolean value: %s", str)
+	result, ok := value.(z3.Bool).AsBool()
+	if !ok {
+		return false, fmt.Errorf("unexpected boolean value: %s", value.String())
 	}
+	return result, nil
 }
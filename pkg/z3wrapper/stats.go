@@ -0,0 +1,210 @@
+package z3wrapper
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ebukreev/go-z3/z3"
+)
+
+// Результаты запроса к solver'у
+const (
+	ResultSat     = "sat"
+	ResultUnsat   = "unsat"
+	ResultUnknown = "unknown"
+)
+
+// QueryStats - статистика одного запроса Check.
+//
+// Обёртка go-z3 не даёт доступа к Z3_solver_get_statistics, поэтому вместо
+// внутренних счётчиков Z3 сохраняется причина ответа unknown, а размер запроса
+// оценивается числом узлов в текстовом (DAG с let-связываниями) представлении
+type QueryStats struct {
+	Index       int           `json:"index"`
+	Duration    time.Duration `json:"duration_ns"`
+	Result      string        `json:"result"`
+	Reason      string        `json:"reason,omitempty"`
+	Constraints int           `json:"constraints"`
+	ExprSize    int           `json:"expr_size"`
+}
+
+// QueryRecord - запись журнала запросов: статистика и запрос в формате SMT-LIB
+type QueryRecord struct {
+	QueryStats
+	SMTLIB string `json:"smtlib"`
+}
+
+// SolverStats - накопленная статистика solver'а
+type SolverStats struct {
+	Queries   int
+	Sat       int
+	Unsat     int
+	Unknown   int
+	TotalTime time.Duration
+	MaxTime   time.Duration
+	History   []QueryStats // последние запросы, см. StatsOptions.History
+}
+
+// StatsOptions задаёт, что статистика сохраняет о каждом запросе. По умолчанию
+// накапливаются только счётчики и время: история растёт с каждым запросом,
+// а для ExprSize приходится печатать и разбирать все утверждения
+type StatsOptions struct {
+	History  int  // сколько последних запросов хранить в History (0 - не хранить)
+	ExprSize bool // вычислять ExprSize запросов (при включённом журнале - всегда)
+}
+
+// Slowest возвращает n самых долгих запросов
+func (st SolverStats) Slowest(n int) []QueryStats {
+	res := append([]QueryStats{}, st.History...)
+	sort.SliceStable(res, func(i, j int) bool { return res[i].Duration > res[j].Duration })
+	if len(res) > n {
+		res = res[:n]
+	}
+	return res
+}
+
+// Stats возвращает статистику запросов solver'а
+func (s *Solver) Stats() SolverStats {
+	return s.stats
+}
+
+// ResetStats обнуляет статистику запросов
+func (s *Solver) ResetStats() {
+	s.stats = SolverStats{}
+}
+
+// SetStatsOptions задаёт, что сохраняется о каждом запросе; уже накопленная
+// история обрезается до нового размера
+func (s *Solver) SetStatsOptions(opts StatsOptions) {
+	s.statsOpts = opts
+	if n := len(s.stats.History) - opts.History; n > 0 {
+		s.stats.History = append([]QueryStats{}, s.stats.History[n:]...)
+	}
+}
+
+// SetQueryLog включает запись журнала запросов в формате JSONL
+// (по записи QueryRecord на строку); nil отключает журнал
+func (s *Solver) SetQueryLog(w io.Writer) {
+	s.log = w
+}
+
+// check выполняет запрос к Z3 и записывает его статистику
+func (s *Solver) check() (bool, error) {
+	query := QueryStats{Index: s.stats.Queries}
+	if s.log != nil || s.statsOpts.History > 0 {
+		assertions := s.solver.Assertions()
+		query.Constraints = len(assertions)
+		if s.log != nil || s.statsOpts.ExprSize {
+			for _, a := range assertions {
+				query.ExprSize += exprSize(a)
+			}
+		}
+	}
+	var smtlib string
+	if s.log != nil {
+		smtlib = s.solver.String() + "(check-sat)\n"
+	}
+
+	start := time.Now()
+	sat, err := s.solver.Check()
+	query.Duration = time.Since(start)
+
+	var unknown *z3.ErrSatUnknown
+	switch {
+	case errors.As(err, &unknown):
+		query.Result = ResultUnknown
+		query.Reason = unknown.Reason
+		s.stats.Unknown++
+	case sat:
+		query.Result = ResultSat
+		s.stats.Sat++
+	default:
+		query.Result = ResultUnsat
+		s.stats.Unsat++
+	}
+	s.stats.Queries++
+	s.stats.TotalTime += query.Duration
+	if query.Duration > s.stats.MaxTime {
+		s.stats.MaxTime = query.Duration
+	}
+	if n := s.statsOpts.History; n > 0 {
+		if len(s.stats.History) == n {
+			s.stats.History = append(s.stats.History[:0], s.stats.History[1:]...)
+		}
+		s.stats.History = append(s.stats.History, query)
+	}
+
+	if s.log != nil {
+		if logErr := writeQueryRecord(s.log, QueryRecord{QueryStats: query, SMTLIB: smtlib}); logErr != nil && err == nil {
+			err = logErr
+		}
+	}
+	return sat, err
+}
+
+func writeQueryRecord(w io.Writer, record QueryRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode query record: %w", err)
+	}
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write query log: %w", err)
+	}
+	return nil
+}
+
+// ReadQueryLog читает журнал запросов, записанный SetQueryLog. Поле SMTLIB
+// каждой записи можно передать z3 напрямую, чтобы воспроизвести запрос
+func ReadQueryLog(path string) ([]QueryRecord, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open query log: %w", err)
+	}
+	defer file.Close()
+
+	var records []QueryRecord
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 64*1024*1024)
+	for scanner.Scan() {
+		var record QueryRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("malformed query log record %d: %w", len(records), err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read query log: %w", err)
+	}
+	return records, nil
+}
+
+// exprSize оценивает размер выражения числом узлов его S-выражения
+func exprSize(value z3.Value) int {
+	term, err := parseSExpr(value.String())
+	if err != nil {
+		return 0
+	}
+	return term.size()
+}
+
+// size возвращает число узлов S-выражения (атомы и применения)
+func (e *sexpr) size() int {
+	if e.isAtom() {
+		return 1
+	}
+	n := 0
+	for _, child := range e.list {
+		n += child.size()
+	}
+	if len(e.list) > 0 && e.list[0].isAtom() {
+		// Имя функции учтено как атом - считаем применение одним узлом
+		return n
+	}
+	return n + 1
+}
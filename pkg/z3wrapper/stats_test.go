@@ -0,0 +1,79 @@
+package z3wrapper
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSolverStatsAndQueryLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.jsonl")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Error creating log: %v", err)
+	}
+
+	solver := NewSolver()
+	defer solver.Close()
+	solver.SetQueryLog(file)
+	solver.SetStatsOptions(StatsOptions{History: 10})
+
+	x := solver.CreateIntVar("x")
+	solver.Assert(x.GT(solver.CreateIntLit(0)))
+	solver.Check()
+	solver.Push()
+	solver.Assert(x.LT(solver.CreateIntLit(0)))
+	solver.Check()
+	solver.Pop()
+	file.Close()
+
+	stats := solver.Stats()
+	if stats.Queries != 2 || stats.Sat != 1 || stats.Unsat != 1 {
+		t.Errorf("Unexpected statistics: %+v", stats)
+	}
+	if stats.History[1].Constraints != 2 || stats.History[1].ExprSize == 0 {
+		t.Errorf("Unexpected query statistics: %+v", stats.History[1])
+	}
+
+	records, err := ReadQueryLog(path)
+	if err != nil {
+		t.Fatalf("Error reading log: %v", err)
+	}
+	if len(records) != 2 || records[1].Result != ResultUnsat {
+		t.Fatalf("Unexpected log records: %+v", records)
+	}
+	if !strings.Contains(records[1].SMTLIB, "(declare-fun x () Int)") || !strings.HasSuffix(records[1].SMTLIB, "(check-sat)\n") {
+		t.Errorf("Expected reproducible SMT-LIB query, got %q", records[1].SMTLIB)
+	}
+}
+
+func TestSolverStatsHistoryIsOptIn(t *testing.T) {
+	solver := NewSolver()
+	defer solver.Close()
+	x := solver.CreateIntVar("x")
+	solver.Assert(x.GT(solver.CreateIntLit(0)))
+
+	solver.Check()
+	if stats := solver.Stats(); stats.Queries != 1 || len(stats.History) != 0 {
+		t.Errorf("Expected counters without history by default, got %+v", stats)
+	}
+
+	solver.SetStatsOptions(StatsOptions{History: 2})
+	for i := 0; i < 3; i++ {
+		solver.Check()
+	}
+	stats := solver.Stats()
+	if len(stats.History) != 2 || stats.History[0].Index != 2 || stats.History[1].Index != 3 {
+		t.Errorf("Expected the last 2 queries in history, got %+v", stats.History)
+	}
+	if stats.History[1].Constraints != 1 || stats.History[1].ExprSize != 0 {
+		t.Errorf("Expected expression size to be computed only on request, got %+v", stats.History[1])
+	}
+
+	solver.SetStatsOptions(StatsOptions{History: 1, ExprSize: true})
+	solver.Check()
+	if stats := solver.Stats(); len(stats.History) != 1 || stats.History[0].ExprSize == 0 {
+		t.Errorf("Expected expression size of the last query, got %+v", stats.History)
+	}
+}